@@ -0,0 +1,66 @@
+package fastrand
+
+import "bytes"
+
+const (
+	cyclicAlphabet = "abcdefghijklmnopqrstuvwxyz"
+	cyclicWindow   = 4
+)
+
+// MaxCyclicLength is the longest pattern Cyclic can produce while keeping
+// every 4-byte window unique.
+const MaxCyclicLength = 26 * 26 * 26 * 26
+
+// Cyclic returns the first length bytes of a de Bruijn sequence over the
+// lowercase alphabet in which every 4-byte window is unique, like pwntools'
+// cyclic. The pattern is deterministic so offsets can be located later with
+// CyclicFind. Lengths above MaxCyclicLength are truncated.
+func Cyclic(length int) []byte {
+	if length <= 0 {
+		return []byte{}
+	}
+	length = min(length, MaxCyclicLength)
+
+	k, n := len(cyclicAlphabet), cyclicWindow
+	out := make([]byte, 0, length)
+	a := make([]int, k*n)
+
+	var db func(t, p int) bool
+	db = func(t, p int) bool {
+		if t > n {
+			if n%p == 0 {
+				for _, v := range a[1 : p+1] {
+					out = append(out, cyclicAlphabet[v])
+					if len(out) == length {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		a[t] = a[t-p]
+		if db(t+1, p) {
+			return true
+		}
+		for j := a[t-p] + 1; j < k; j++ {
+			a[t] = j
+			if db(t+1, t) {
+				return true
+			}
+		}
+		return false
+	}
+	db(1, 1)
+
+	return out
+}
+
+// CyclicFind returns the offset of subsequence within pattern, or -1 if it
+// does not occur. With a pattern from Cyclic any 4-byte subsequence occurs at
+// most once, so the offset is unambiguous.
+func CyclicFind(pattern, subsequence []byte) int {
+	if len(subsequence) == 0 {
+		return -1
+	}
+	return bytes.Index(pattern, subsequence)
+}
@@ -0,0 +1,60 @@
+package fastrand_test
+
+import (
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestCyclic(t *testing.T) {
+	t.Run("Prefix", func(t *testing.T) {
+		if got := string(fastrand.Cyclic(20)); got != "aaaabaaacaaadaaaeaaa" {
+			t.Errorf("Unexpected pattern prefix %q", got)
+		}
+	})
+
+	t.Run("UniqueWindows", func(t *testing.T) {
+		pattern := fastrand.Cyclic(fastrand.MaxCyclicLength)
+		if len(pattern) != fastrand.MaxCyclicLength {
+			t.Fatalf("Expected length %d, got %d", fastrand.MaxCyclicLength, len(pattern))
+		}
+		seen := make(map[string]struct{}, len(pattern))
+		for i := 0; i+4 <= len(pattern); i++ {
+			window := string(pattern[i : i+4])
+			if _, dup := seen[window]; dup {
+				t.Fatalf("Window %q at offset %d is repeated", window, i)
+			}
+			seen[window] = struct{}{}
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		if got := len(fastrand.Cyclic(fastrand.MaxCyclicLength + 10)); got != fastrand.MaxCyclicLength {
+			t.Errorf("Expected truncation to %d, got %d", fastrand.MaxCyclicLength, got)
+		}
+		if got := len(fastrand.Cyclic(0)); got != 0 {
+			t.Errorf("Expected empty pattern, got length %d", got)
+		}
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		pattern := fastrand.Cyclic(1000)
+		for _, offset := range []int{0, 1, 37, 500, 996} {
+			if got := fastrand.CyclicFind(pattern, pattern[offset:offset+4]); got != offset {
+				t.Errorf("Expected offset %d, got %d", offset, got)
+			}
+		}
+		if got := fastrand.CyclicFind(pattern, []byte("ZZZZ")); got != -1 {
+			t.Errorf("Expected -1 for a missing subsequence, got %d", got)
+		}
+	})
+
+	t.Run("Keyword", func(t *testing.T) {
+		if got := fastrand.RandomizerString("{RAND;50;CYCLIC}"); got != string(fastrand.Cyclic(50)) {
+			t.Errorf("Expected keyword to emit the cyclic pattern, got %q", got)
+		}
+		if got := fastrand.Randomizer([]byte("{RAND;1k;CYCLIC}")); len(got) != 1024 {
+			t.Errorf("Expected 1024 bytes, got %d", len(got))
+		}
+	})
+}
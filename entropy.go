@@ -0,0 +1,579 @@
+package fastrand
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Per-unit estimates for keywords whose output is not a plain charset pick.
+const (
+	uuidEntropyBits   = 122
+	semverEntropyBits = 16.6 // log2(20 * 50 * 100) for the major.minor.patch core
+	domainLabelBits   = 33.6 // log2(8) for the length plus 6.5 letters on average
+	pathSegmentBits   = 30.0 // average String(Int(3, 10), filenameChars) segment
+	utf8RuneBits      = 16.2 // two bits for the width plus the mean log2 of each width's range
+	badUTF8ByteBits   = 4.0
+	emailPlusTagBits  = 27.7 // log2(6) for the length plus 5.5 lowercase letters
+)
+
+func EntropyBits(template []byte) float64 {
+	return defaultEngine.EntropyBits(template)
+}
+
+// EntropyBits estimates how many bits of entropy an expansion of template
+// carries, by summing log2 of each tag's choice space. Literal text adds
+// nothing. Length ranges and choices are averaged over the lengths they can
+// pick, and keyword choices over the keywords. Tags that fall back to the
+// default charset (empty, unknown or disabled keywords) are estimated as
+// such, and custom keywords, whose output is opaque, are estimated as
+// CharsAll strings of the tag's length. Digests are capped at their output
+// size.
+func (e *FastEngine) EntropyBits(template []byte) float64 {
+	template = e.decodeInput(template)
+
+	var bits float64
+	cursor := 0
+	for {
+		startIndex := bytes.Index(template[cursor:], startTag)
+		if startIndex == -1 {
+			return bits
+		}
+		startIndex += cursor
+		endIndex := findTagEnd(template[startIndex:])
+		if endIndex == -1 {
+			return bits
+		}
+		if tag := template[startIndex : startIndex+endIndex]; isWellFormedTag(tag) {
+			bits += e.tagEntropyBits(tag)
+		}
+		cursor = startIndex + endIndex + 1
+	}
+}
+
+// tagEntropyBits mirrors the tag parsing of parseAndReplaceFast.
+func (e *FastEngine) tagEntropyBits(tag []byte) float64 {
+	tag = tag[len(startTag):]
+	if bytes.HasPrefix(tag, startTagOpt) {
+		tag = tag[len(startTagOpt):]
+	}
+	if len(tag) == 0 {
+		return charsetBits(lengthBounds{e.minLength, e.maxLength}.clamp(e.defaultLength), e.defaultCharset)
+	}
+	tag = tag[1:]
+
+	var typeKeyword, lenPart []byte
+	if sepIndex := bytes.IndexByte(tag, sepTag); sepIndex == -1 {
+		lenPart = tag
+	} else {
+		lenPart = tag[:sepIndex]
+		typeKeyword = tag[sepIndex+1:]
+	}
+
+	bounds := e.lengthBoundsFor(typeKeyword)
+	lengths, sized := e.estimateLengths(lenPart, bounds)
+	if lengths == nil {
+		lengths = []int{e.defaultLength}
+		if typeKeyword == nil {
+			typeKeyword = lenPart
+		}
+	}
+
+	var arg []byte
+	if argIndex := bytes.IndexByte(typeKeyword, sepTag); argIndex != -1 {
+		arg = typeKeyword[argIndex+1:]
+		typeKeyword = typeKeyword[:argIndex]
+	}
+
+	keywords := [][]byte{typeKeyword}
+	if e.keywordChoicesEnabled && bytes.Contains(typeKeyword, []byte(",")) {
+		var validChoices [][]byte
+		for _, choice := range bytes.Split(typeKeyword, []byte(",")) {
+			if e.isKnownKeyword(choice) {
+				validChoices = append(validChoices, choice)
+			}
+		}
+		if len(validChoices) > 0 {
+			keywords = validChoices
+		}
+	}
+
+	var total float64
+	for _, keyword := range keywords {
+		upcased := e.resolveAlias(strings.ToUpper(string(keyword)))
+		keywordSized := sized && isSizedKeyword(upcased)
+		kb, clamped := e.keywordLengthBounds[upcased]
+		for _, length := range lengths {
+			if sized && !keywordSized {
+				length = e.defaultLength
+			}
+			if clamped {
+				length = kb.clamp(length)
+			} else if !keywordSized {
+				length = bounds.clamp(length)
+			}
+			total += e.keywordEntropyBits(upcased, arg, length)
+		}
+	}
+	return total / float64(len(keywords)*len(lengths))
+}
+
+// estimateLengths returns every length lenPart can resolve to, or nil.
+func (e *FastEngine) estimateLengths(lenPart []byte, bounds lengthBounds) (lengths []int, sized bool) {
+	if e.lengthChoicesEnabled && bytes.Contains(lenPart, []byte(",")) {
+		for _, part := range bytes.Split(lenPart, []byte(",")) {
+			if l, ok := parseLengthFast(part); ok && bounds.contain(l) {
+				lengths = append(lengths, l)
+			}
+		}
+		if lengths != nil {
+			return lengths, false
+		}
+	}
+
+	if e.rangesEnabled {
+		if minX, maxX, ok, _ := e.parseRange(lenPart, bounds); ok {
+			for l := minX; l <= maxX; l++ {
+				lengths = append(lengths, l)
+			}
+			return lengths, false
+		}
+	}
+
+	if l, ok := parseLengthFast(lenPart); ok && bounds.contain(l) {
+		return []int{l}, false
+	}
+	if l, ok := parseSizeSuffix(lenPart); ok && l <= e.maxByteLength {
+		return []int{l}, true
+	}
+	return nil, false
+}
+
+func (e *FastEngine) keywordEntropyBits(keyword string, arg []byte, length int) float64 {
+	if _, exists := e.customKeywords[keyword]; exists {
+		return charsetBits(length, CharsAll)
+	}
+	if charset := e.customCharsets[keyword]; len(charset) > 0 {
+		return charsetBits(length, charset)
+	}
+	if !e.enabledKeywords[keyword] {
+		return charsetBits(length, e.getCharset(kwABR, e.defaultCharset))
+	}
+
+	switch keyword {
+	case "ABL":
+		return charsetBits(length, e.getCharset(kwABL, CharsAlphabetLower))
+	case "ABU":
+		return charsetBits(length, e.getCharset(kwABU, CharsAlphabetUpper))
+	case "ABR":
+		return charsetBits(length, e.getCharset(kwABR, CharsAlphabet))
+	case "DIGIT":
+		return charsetBits(length, e.getCharset(kwDIGIT, CharsDigits))
+	case "BASE58":
+		return charsetBits(length, e.getCharset(kwBASE58, CharsBase58))
+	case "QUOTED":
+		return charsetBits(length, e.getCharset(kwQUOTED, quotedChars))
+	case "NULL":
+		return charsetBits(length, e.getCharset(kwNULL, CharsNull))
+	case "SPACE", "CYCLIC", "RULE", "SPLIT":
+		return 0
+	case "UUID":
+		if bytes.EqualFold(arg, []byte("v7")) {
+			return 74
+		}
+		if bytes.EqualFold(arg, []byte("v1")) {
+			// The clock sequence and the node without its multicast bit.
+			return 14 + 47
+		}
+		return uuidEntropyBits
+	case "BYTES", "HEXDUMP", "MAGIC":
+		return float64(8 * length)
+	case "HEX":
+		if length <= 0 {
+			length = e.defaultLength
+		}
+		return float64(8 * length)
+	case "BYTESBIAS":
+		pct, err := strconv.Atoi(string(arg))
+		if err != nil {
+			pct = defaultBiasPercent
+		}
+		return float64(length) * biasedByteBits(pct)
+	case "FLAG":
+		return 1
+	case "SQLI":
+		return choiceBits(len(e.sqliPayloads))
+	case "XSS":
+		return choiceBits(len(e.xssPayloads))
+	case "STATUS":
+		return choiceBits(len(statusCodesFor(arg)))
+	case "OID":
+		length = max(length, 2)
+		return choiceBits(2*40+maxOIDArc+1) + float64(length-2)*choiceBits(maxOIDArc+1)
+	case "BASEN":
+		base, maxValue, hasMax := parseBaseN(arg)
+		if hasMax {
+			return choiceBits(maxValue + 1)
+		}
+		return float64(length) * choiceBits(base)
+	case "GEO":
+		box := geoBoxFor(arg)
+		return choiceBits(int((box.maxLat-box.minLat)*1e6)) + choiceBits(int((box.maxLon-box.minLon)*1e6))
+	case "CRON":
+		// Roughly the form choice plus a value or two per field.
+		fields := len(cronFields)
+		if bytes.EqualFold(arg, []byte("seconds")) {
+			fields++
+		}
+		return float64(fields) * (choiceBits(5) + 5)
+	case "PROTOWIRE":
+		// Field number and type, plus the mean payload of the four types.
+		field := choiceBits(maxProtoField) + choiceBits(len(protoWireTypes))
+		payload := (32 + 64 + 8*maxProtoBytesField/2 + 32) / 4.0
+		return float64(length) * (field + payload)
+	case "METHOD":
+		_, weights := e.methodChoices(arg)
+		return weightedBits(weights)
+	case "CSVROWS":
+		columns, _ := csvRowsArgs(arg)
+		var row float64
+		for _, column := range columns {
+			keyword := e.resolveAlias(strings.ToUpper(string(column)))
+			row += e.keywordEntropyBits(keyword, nil, e.lengthBoundsFor([]byte(keyword)).clamp(e.defaultLength))
+		}
+		return float64(length) * row
+	case "JSONPOINTER":
+		// A quarter of the segments are indices, the rest keys of 1 to 8
+		// characters.
+		key := choiceBits(8) + 4.5*charsetBits(1, jsonPointerKeyChars)
+		segment := weightedBits([]float64{1, 3}) + (choiceBits(maxJSONPointerIndex+1)+3*key)/4
+		return float64(length) * segment
+	case "XML":
+		depth := defaultXMLDepth
+		if d, err := strconv.Atoi(string(bytes.TrimSpace(arg))); err == nil {
+			depth = min(max(d, 1), maxXMLDepth)
+		}
+		return xmlElementBits(depth)
+	case "REGEX":
+		// Counted as one literal per atom, ignoring the choice of structure.
+		return charsetBits(max(length, 1), regexLiterals)
+	case "DNS":
+		switch strings.ToUpper(string(bytes.TrimSpace(arg))) {
+		case "AAAA":
+			return 128
+		case "MX":
+			// Preference, prefix, label length and letters, and TLD.
+			label := (charsetBits(3, CharsAlphabetLower) + charsetBits(10, CharsAlphabetLower)) / 2
+			return choiceBits(6) + choiceBits(len(mxHostPrefixes)) + choiceBits(8) + label + choiceBits(len(hostnameTLDs))
+		case "TXT":
+			return charsetBits(length, txtChars)
+		}
+		return 32
+	case "STEP":
+		_, _, count, _ := parseStep(arg)
+		if count > math.MaxInt {
+			return math.Log2(float64(count))
+		}
+		return choiceBits(int(count))
+	case "COLOR":
+		if colors := e.palette(arg); len(colors) > 0 {
+			return choiceBits(len(colors))
+		}
+		return 24
+	case "IPV4":
+		return 32
+	case "IPV6":
+		return 128
+	case "MAC":
+		if parseOUI(arg) != nil {
+			return 24
+		}
+		return 47
+	case "EMAIL":
+		if length <= 0 {
+			length = 8
+		}
+		plus, depth := emailModifiers(arg)
+		bits := charsetBits(length, e.getCharset(kwABL, CharsAlphabetLower))
+		if e.emailDomainMode == EmailDomainRandom {
+			bits += float64(max(depth, 1))*domainLabelBits + choiceBits(len(hostnameTLDs))
+		} else {
+			bits += choiceBits(len(e.providers())) + float64(max(depth-1, 0))*domainLabelBits
+		}
+		if plus {
+			bits += emailPlusTagBits
+		}
+		return bits
+	case "SHA256", "SHA1", "MD5":
+		input := float64(8 * length)
+		if len(arg) > 0 {
+			input = e.EntropyBits(arg)
+		}
+		return math.Min(input, float64(digestBits(keyword)))
+	case "SEMVER":
+		return semverEntropyBits
+	case "SEMVERRANGE":
+		switch form := strings.ToLower(string(bytes.TrimSpace(arg))); form {
+		case "caret", "tilde":
+			return semverEntropyBits
+		case "range", "hyphen":
+			return semverEntropyBits + semverBumpBits
+		case "compare":
+			return semverEntropyBits + 2
+		}
+		// Counted without the upper bound, which only some forms have.
+		return choiceBits(len(semverRangeForms)) + semverEntropyBits
+	case "FILENAME":
+		extensions := 0
+		for _, ext := range bytes.Split(arg, []byte(",")) {
+			ext = bytes.TrimLeft(bytes.TrimSpace(ext), ".")
+			if len(ext) > 0 && isSafeFilename(ext) {
+				extensions++
+			}
+		}
+		if extensions == 0 {
+			extensions = len(filenameExtensions)
+		}
+		return charsetBits(length, filenameChars) + choiceBits(extensions)
+	case "PATH":
+		return float64(length) * pathSegmentBits
+	case "PID":
+		if bytes.EqualFold(arg, []byte("self")) {
+			return 0
+		}
+		return choiceBits(maxPID)
+	case "UTF8":
+		return float64(length) * utf8RuneBits
+	case "UNICODE":
+		return float64(length) * choiceBits(printableRunes(unicodeBlockFor(arg)))
+	case "BRACKETS":
+		// Each opener picks a type; the shape of the walk is not counted.
+		return float64((length+1)/2) * choiceBits(len(parseBracketTypes(arg)))
+	case "ISBN":
+		if string(arg) == "10" {
+			return charsetBits(9, CharsDigits)
+		}
+		return 1 + charsetBits(9, CharsDigits)
+	case "SHORTID":
+		return charsetBits(length, CharsAlphabetDigits)
+	case "HEADERS":
+		count := min(length, len(HeaderNames))
+		bits := 0.0
+		for i := 0; i < count; i++ {
+			bits += choiceBits(len(HeaderNames) - i)
+		}
+		return bits + float64(count)*(choiceBits(32)+16.5*choiceBits(len(headerValueChars)))
+	case "BADUTF8":
+		return float64(length) * badUTF8ByteBits
+	case "RFC3339":
+		seconds := e.timeRangeEnd.Sub(e.timeRangeStart).Seconds()
+		return math.Log2(math.Max(seconds, 1)) + choiceBits(len(rfc3339Offsets))
+	case "LOCALE":
+		switch {
+		case bytes.EqualFold(arg, []byte("lang")):
+			return choiceBits(len(languageLocales))
+		case bytes.EqualFold(arg, []byte("region")):
+			return choiceBits(len(regionLocales))
+		}
+		return choiceBits(len(Locales))
+	case "UNIQUEENUM":
+		_, values, _ := bytes.Cut(arg, []byte{sepTag})
+		return choiceBits(bytes.Count(values, []byte(",")) + 1)
+	case "IDENT":
+		return choiceBits(len(identStartChars)) + charsetBits(length-1, identChars)
+	case "ONCE":
+		// Repeats of a name are counted again, so this is an upper bound.
+		_, tag := onceTag(arg)
+		return e.EntropyBits(tag)
+	case "REMEMBER":
+		_, tag := onceTag(arg)
+		return e.EntropyBits(tag)
+	case "RECALL":
+		// Recalled values were counted where they were remembered.
+		return 0
+	case "HASHPICK":
+		key, choices := hashPickArgs(arg)
+		if !bytes.Contains(key, startTag) {
+			return 0
+		}
+		return min(e.EntropyBits(key), choiceBits(len(choices)))
+	case "SPONGE":
+		if len(arg) == 0 {
+			return charsetBits(length, CharsAlphabet)
+		}
+		if bytes.Contains(arg, startTag) {
+			// Case flips of generated letters are not counted.
+			return e.EntropyBits(arg)
+		}
+		letters := 0
+		for _, r := range string(arg) {
+			if unicode.IsLetter(r) {
+				letters++
+			}
+		}
+		return float64(letters)
+	case "QUERY":
+		// Keys are distinct, so this slightly overestimates their share.
+		key := choiceBits(3) + 2*choiceBits(len(CharsAlphabetLower))
+		value := choiceBits(8) + 4.5*choiceBits(len(CharsAll))
+		return float64(length) * (key + value)
+	case "EMOJI":
+		return float64(length) * choiceBits(len(Emoji))
+	case "JSONVAL":
+		return jsonValueBits(strings.ToLower(string(arg)))
+	case "LATENCY":
+		// The differential entropy of the log-normal, in steps of the
+		// output resolution.
+		median, logSigma, unit, ok := parseLatency(arg)
+		if !ok || logSigma == 0 {
+			return 0
+		}
+		resolution := math.Pow10(-unit.decimals) / unit.scale
+		nats := math.Log(median/resolution) + 0.5 + math.Log(logSigma*math.Sqrt(2*math.Pi))
+		return max(nats/math.Ln2, 0)
+	case "MARKDOWN":
+		return markdownBits(strings.ToLower(string(bytes.TrimSpace(arg))), max(length, 1))
+	case "YAMLVAL":
+		return yamlValueBits(strings.ToLower(string(bytes.TrimSpace(arg))))
+	case "CRLFINJECT":
+		// Only the base and the choice of a single sequence are counted.
+		return e.EntropyBits(arg) + choiceBits(len(e.injectSequences))
+	case "MIME":
+		if types, ok := e.mimeTypes[strings.ToLower(string(arg))]; ok && len(types) > 0 {
+			return choiceBits(len(types))
+		}
+		return choiceBits(len(e.mimeTypes[""]))
+	default:
+		return charsetBits(length, e.getCharset(kwABR, e.defaultCharset))
+	}
+}
+
+func charsetBits(length int, charset CharsList) float64 {
+	return float64(length) * choiceBits(len(charset))
+}
+
+// weightedBits is the Shannon entropy of a weighted choice.
+func weightedBits(weights []float64) float64 {
+	var total, bits float64
+	for _, w := range weights {
+		total += w
+	}
+	for _, w := range weights {
+		if p := w / total; p > 0 {
+			bits -= p * math.Log2(p)
+		}
+	}
+	return bits
+}
+
+func choiceBits(n int) float64 {
+	if n <= 1 {
+		return 0
+	}
+	return math.Log2(float64(n))
+}
+
+func digestBits(keyword string) int {
+	switch keyword {
+	case "SHA1":
+		return 160
+	case "MD5":
+		return 128
+	}
+	return 256
+}
+
+// biasedByteBits is the entropy of one BytesBiased byte at pct percent.
+func biasedByteBits(pct int) float64 {
+	p := math.Min(math.Max(float64(pct), 0), 100) / 100
+	inSet := make(map[byte]bool, len(BoundaryBytes))
+	for _, b := range BoundaryBytes {
+		inSet[b] = true
+	}
+
+	var bits float64
+	for v := 0; v < 256; v++ {
+		prob := (1 - p) / 256
+		if inSet[byte(v)] {
+			prob += p / float64(len(inSet))
+		}
+		if prob > 0 {
+			bits -= prob * math.Log2(prob)
+		}
+	}
+	return bits
+}
+
+func jsonValueBits(typ string) float64 {
+	switch typ {
+	case "number":
+		return 1 + (choiceBits(2000001)+52)/2
+	case "bool":
+		return 1
+	case "null":
+		return 0
+	case "array":
+		var scalar float64
+		for _, t := range jsonScalarTypes {
+			scalar += jsonValueBits(t)
+		}
+		scalar /= float64(len(jsonScalarTypes))
+		return choiceBits(6) + 2.5*(choiceBits(len(jsonScalarTypes))+scalar)
+	default:
+		return choiceBits(12) + 6.5*choiceBits(len(CharsAll))
+	}
+}
+
+// xmlElementBits estimates the entropy of an XML element at depth.
+func xmlElementBits(depth int) float64 {
+	name := charsetBits(1, xmlNameStartChars) + choiceBits(8) + 3.5*charsetBits(1, xmlNameChars)
+	text := choiceBits(12) + 6.5*charsetBits(1, xmlTextChars)
+	attrs := name + choiceBits(8) + 4.5*charsetBits(1, xmlTextChars)
+	if depth <= 1 {
+		return name + attrs + 0.75*text
+	}
+	return name + attrs + text + 1.5*(xmlElementBits(depth-1)+text/2)
+}
+
+func markdownBits(kind string, length int) float64 {
+	words := float64(length) * choiceBits(len(markdownWords))
+	switch kind {
+	case "heading":
+		return choiceBits(6) + words
+	case "list":
+		return 1 + choiceBits(4) + 3.5*words
+	case "emphasis":
+		return words + choiceBits(length) + choiceBits(max(length-1, 1))
+	case "link":
+		label := choiceBits(8) + (charsetBits(3, CharsAlphabetLower)+charsetBits(10, CharsAlphabetLower))/2
+		host := choiceBits(len(linkHostPrefixes)) + label + choiceBits(len(hostnameTLDs))
+		return words + host + choiceBits(len(markdownWords))
+	}
+	var bits float64
+	for _, k := range markdownKinds {
+		bits += markdownBits(k, length)
+	}
+	return choiceBits(len(markdownKinds)) + bits/float64(len(markdownKinds))
+}
+
+func yamlValueBits(typ string) float64 {
+	switch typ {
+	case "int":
+		return choiceBits(2000001)
+	case "float":
+		return 52
+	case "bool":
+		return 1
+	case "string":
+		// A quarter of the strings are lookalikes, the rest 1 to 12 characters.
+		random := choiceBits(12) + 6.5*charsetBits(1, yamlStringChars)
+		return weightedBits([]float64{1, 3}) + (choiceBits(len(yamlLookalikes))+3*random)/4
+	}
+	var scalar float64
+	for _, t := range yamlScalarTypes {
+		scalar += yamlValueBits(t)
+	}
+	return choiceBits(len(yamlScalarTypes)) + scalar/float64(len(yamlScalarTypes))
+}
@@ -0,0 +1,48 @@
+package fastrand_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestEntropyBits(t *testing.T) {
+	testCases := []struct {
+		template string
+		want     float64
+	}{
+		{"plain text only", 0},
+		{"{RAND;16;HEX}", 128},
+		{"{RAND;8;BYTES}", 64},
+		{"{RAND;10;DIGIT}", 10 * math.Log2(10)},
+		{"{RAND;4;ABL}", 4 * math.Log2(26)},
+		{"{RAND;;UUID}", 122},
+		{"{RAND;;IPV4}", 32},
+		{"{RAND;12;SPACE}", 0},
+		{"{RAND;1k;BYTES}", 8192},
+		{"{RAND;2-4;DIGIT}", 3 * math.Log2(10)},
+		{"{RAND;2,6;ABU}", 4 * math.Log2(26)},
+		{"{RAND;4;BYTES,DIGIT}", (32 + 4*math.Log2(10)) / 2},
+		{"{RAND;8;NOSUCHKEYWORD}", 8 * math.Log2(float64(len(fastrand.CharsAll)))},
+		{"{RAND}", 16 * math.Log2(float64(len(fastrand.CharsAll)))},
+		{"id={RAND;4;DIGIT}&t={RAND;4;DIGIT}", 8 * math.Log2(10)},
+		{"{RAND;;MD5;{RAND;8;DIGIT}}", 8 * math.Log2(10)},
+		{"{RAND;;MD5;{RAND;99;BYTES}}", 128},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			got := fastrand.EntropyBits([]byte(tc.template))
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("Expected %.3f bits, got %.3f", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEntropyBitsCustomCharset(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithCustomCharset("DIGIT", []byte("01")))
+	if got := engine.EntropyBits([]byte("{RAND;20;DIGIT}")); got != 20 {
+		t.Errorf("Expected 20 bits for a binary charset, got %f", got)
+	}
+}
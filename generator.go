@@ -0,0 +1,49 @@
+package fastrand
+
+import (
+	"errors"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+// ErrShortBuffer is returned by Generator.Generate when the destination is
+// too small for the expansion.
+var ErrShortBuffer = errors.New("fastrand: destination buffer too small")
+
+// Generator expands templates into caller-owned buffers. It keeps its scratch
+// buffer between calls, so once warmed up Generate does not allocate for
+// templates made of charset keywords. A Generator is not safe for concurrent
+// use; give each goroutine its own.
+type Generator struct {
+	engine *FastEngine
+	buffer bytebufferpool.ByteBuffer
+	state  callState
+}
+
+func NewGenerator() *Generator {
+	return defaultEngine.NewGenerator()
+}
+
+func (e *FastEngine) NewGenerator() *Generator {
+	return &Generator{engine: e}
+}
+
+// Generate expands template into dst and returns the number of bytes
+// written. It never grows dst: if the expansion does not fit, dst is left
+// untouched and Generate returns the size needed along with ErrShortBuffer.
+func (g *Generator) Generate(dst, template []byte) (int, error) {
+	e := g.engine
+	g.buffer.Reset()
+	g.state.reset()
+	if e.passthrough(template) {
+		_, _ = g.buffer.Write(template)
+	} else {
+		e.reserve(&g.buffer, template)
+		e.randomizeState(template, &g.buffer, e.outputEncoding, &g.state)
+	}
+
+	if g.buffer.Len() > len(dst) {
+		return g.buffer.Len(), ErrShortBuffer
+	}
+	return copy(dst, g.buffer.B), nil
+}
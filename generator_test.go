@@ -0,0 +1,62 @@
+package fastrand_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestGenerator(t *testing.T) {
+	generator := fastrand.NewGenerator()
+	template := []byte("id={RAND;16;DIGIT}&name={RAND;8;ABL}")
+	pattern := regexp.MustCompile(`^id=[0-9]{16}&name=[a-z]{8}$`)
+
+	t.Run("FitsBuffer", func(t *testing.T) {
+		dst := make([]byte, 64)
+		for i := 0; i < 100; i++ {
+			n, err := generator.Generate(dst, template)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !pattern.Match(dst[:n]) {
+				t.Fatalf("Unexpected output %q", dst[:n])
+			}
+		}
+	})
+
+	t.Run("ShortBuffer", func(t *testing.T) {
+		dst := make([]byte, 10)
+		n, err := generator.Generate(dst, template)
+		if !errors.Is(err, fastrand.ErrShortBuffer) {
+			t.Fatalf("Expected ErrShortBuffer, got %v", err)
+		}
+		if n != len("id=&name=")+16+8 {
+			t.Errorf("Expected the needed size, got %d", n)
+		}
+		if string(dst) != string(make([]byte, 10)) {
+			t.Errorf("Expected dst to be untouched, got %q", dst)
+		}
+	})
+
+	t.Run("Passthrough", func(t *testing.T) {
+		dst := make([]byte, 5)
+		if n, err := generator.Generate(dst, []byte("plain")); err != nil || string(dst[:n]) != "plain" {
+			t.Errorf("Expected plain text to be copied, got %q, %v", dst[:n], err)
+		}
+	})
+
+	t.Run("UniqueSetsPerCall", func(t *testing.T) {
+		dst := make([]byte, 16)
+		for i := 0; i < 20; i++ {
+			n, err := generator.Generate(dst, []byte("{RAND;;UNIQUEENUM;s;a,b}{RAND;;UNIQUEENUM;s;a,b}"))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out := string(dst[:n]); out != "ab" && out != "ba" {
+				t.Fatalf("Expected each call to start a fresh set, got %q", out)
+			}
+		}
+	})
+}
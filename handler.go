@@ -0,0 +1,73 @@
+package fastrand
+
+import (
+	"bytes"
+	"net/http"
+)
+
+type HandlerOption func(*templateHandler)
+
+type templateHandler struct {
+	engine      *FastEngine
+	template    []byte
+	contentType string
+	statusCode  int
+}
+
+func TemplateHandler(template []byte, opts ...HandlerOption) http.Handler {
+	return defaultEngine.TemplateHandler(template, opts...)
+}
+
+// TemplateHandler serves an expansion of template for every request. The
+// handler may serve requests concurrently: in place of the shared FastSource
+// or SecureSource it draws from its own locked generator. The body is streamed
+// through RandomizerStream rather than buffered.
+func (e *FastEngine) TemplateHandler(template []byte, opts ...HandlerOption) http.Handler {
+	h := &templateHandler{
+		engine:      e.withSource(concurrentSource(e.src)),
+		template:    append([]byte(nil), template...),
+		contentType: defaultContentType(e.outputEncoding),
+		statusCode:  http.StatusOK,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *templateHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	if h.contentType != "" {
+		w.Header().Set("Content-Type", h.contentType)
+	}
+	w.WriteHeader(h.statusCode)
+	_ = h.engine.RandomizerStream(bytes.NewReader(h.template), w)
+}
+
+func WithContentType(contentType string) HandlerOption {
+	return func(h *templateHandler) {
+		h.contentType = contentType
+	}
+}
+
+func WithStatusCode(code int) HandlerOption {
+	return func(h *templateHandler) {
+		if code >= 100 && code <= 999 {
+			h.statusCode = code
+		}
+	}
+}
+
+func defaultContentType(encoding RandomizerEncoding) string {
+	switch encoding {
+	case RandomizerEncodingHTML:
+		return "text/html; charset=utf-8"
+	case RandomizerEncodingURL:
+		return "application/x-www-form-urlencoded"
+	case RandomizerEncodingCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
@@ -0,0 +1,114 @@
+package fastrand_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestTemplateHandler(t *testing.T) {
+	t.Run("DistinctBodies", func(t *testing.T) {
+		server := httptest.NewServer(fastrand.TemplateHandler([]byte("token={RAND;32;HEX}")))
+		defer server.Close()
+
+		seen := make(map[string]struct{})
+		for i := 0; i < 10; i++ {
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil {
+				t.Fatalf("Reading body failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", resp.StatusCode)
+			}
+			if len(body) != len("token=")+64 {
+				t.Errorf("Unexpected body %q", body)
+			}
+			seen[string(body)] = struct{}{}
+		}
+		if len(seen) != 10 {
+			t.Errorf("Expected 10 distinct bodies, got %d", len(seen))
+		}
+	})
+
+	t.Run("ContentTypeAndStatus", func(t *testing.T) {
+		handler := fastrand.TemplateHandler(
+			[]byte(`{"id":"{RAND;UUID}"}`),
+			fastrand.WithContentType("application/json"),
+			fastrand.WithStatusCode(http.StatusTeapot),
+		)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusTeapot {
+			t.Errorf("Expected status %d, got %d", http.StatusTeapot, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected content type application/json, got %q", ct)
+		}
+		if rec.Body.Len() != len(`{"id":""}`)+36 {
+			t.Errorf("Unexpected body %q", rec.Body.String())
+		}
+	})
+
+	t.Run("OutputEncoding", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithOutputEncoding(fastrand.RandomizerEncodingHTML))
+		rec := httptest.NewRecorder()
+		engine.TemplateHandler([]byte("<b>{RAND;4;DIGIT}</b>")).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("Expected HTML content type, got %q", ct)
+		}
+		if body := rec.Body.String(); len(body) != len("&lt;b&gt;&lt;/b&gt;")+4 {
+			t.Errorf("Expected HTML-escaped body, got %q", body)
+		}
+	})
+
+	t.Run("Streams", func(t *testing.T) {
+		template := bytes.Repeat([]byte("{RAND;8;HEX}\n"), 10000)
+		rec := &countingRecorder{ResponseRecorder: httptest.NewRecorder()}
+		fastrand.TemplateHandler(template).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Body.Len() != 10000*17 {
+			t.Fatalf("Expected %d bytes, got %d", 10000*17, rec.Body.Len())
+		}
+		if rec.writes < 2 {
+			t.Errorf("Expected a large body to be written in several chunks, got %d writes", rec.writes)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		handler := fastrand.TemplateHandler([]byte("{RAND;16;ABL}"))
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				if rec.Body.Len() != 16 {
+					t.Errorf("Expected body of length 16, got %q", rec.Body.String())
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+type countingRecorder struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (r *countingRecorder) Write(p []byte) (int, error) {
+	r.writes++
+	return r.ResponseRecorder.Write(p)
+}
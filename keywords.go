@@ -0,0 +1,1456 @@
+package fastrand
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+// groupMimeTypes indexes types by category, with every type under "".
+func groupMimeTypes(types []string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, t := range types {
+		slash := strings.IndexByte(t, '/')
+		if slash <= 0 {
+			continue
+		}
+		category := strings.ToLower(t[:slash])
+		groups[category] = append(groups[category], t)
+		groups[""] = append(groups[""], t)
+	}
+	return groups
+}
+
+func (e *FastEngine) generateMimeType(category []byte) string {
+	r := e.src
+	if types, ok := e.mimeTypes[strings.ToLower(string(category))]; ok && len(types) > 0 {
+		return pick(r, types)
+	}
+	if all := e.mimeTypes[""]; len(all) > 0 {
+		return pick(r, all)
+	}
+	return "application/octet-stream"
+}
+
+func generateDigest(keyword, input []byte) []byte {
+	var h hash.Hash
+	switch {
+	case bytes.EqualFold(keyword, kwSHA1):
+		h = sha1.New()
+	case bytes.EqualFold(keyword, kwMD5):
+		h = md5.New()
+	default:
+		h = sha256.New()
+	}
+	_, _ = h.Write(input)
+	sum := h.Sum(nil)
+	out := make([]byte, hex.EncodedLen(len(sum)))
+	hex.Encode(out, sum)
+	return out
+}
+
+// formatIPv4 renders ip as a dotted quad, or as an "int" or "hex" value.
+func formatIPv4(ip net.IP, format []byte) []byte {
+	value := binary.BigEndian.Uint32(ip.To4())
+	switch {
+	case bytes.EqualFold(format, []byte("int")):
+		return strconv.AppendUint(nil, uint64(value), 10)
+	case bytes.EqualFold(format, []byte("hex")):
+		return fmt.Appendf(nil, "0x%08X", value)
+	default:
+		return []byte(ip.String())
+	}
+}
+
+var semverPrereleases = []string{"alpha", "beta", "rc"}
+
+// appendSemver appends MAJOR.MINOR.PATCH with optional pre and build parts.
+func appendSemver(r Source, out []byte, modifier []byte) []byte {
+	out = strconv.AppendInt(out, int64(intN(r, 20)), 10)
+	out = append(out, '.')
+	out = strconv.AppendInt(out, int64(intN(r, 50)), 10)
+	out = append(out, '.')
+	out = strconv.AppendInt(out, int64(intN(r, 100)), 10)
+
+	if len(modifier) > 0 {
+		modifier = bytes.ToLower(modifier)
+	}
+	if bytes.Contains(modifier, []byte("pre")) && randBool(r) {
+		out = append(out, '-')
+		out = append(out, pick(r, semverPrereleases)...)
+		out = append(out, '.')
+		out = strconv.AppendInt(out, int64(intRange(r, 1, 20)), 10)
+	}
+	if bytes.Contains(modifier, []byte("build")) {
+		out = append(out, "+build."...)
+		out = hex.AppendEncode(out, randBytes(r, 4))
+	}
+	return out
+}
+
+// semverRangeForms are the constraint shapes SEMVERRANGE emits.
+var semverRangeForms = []string{"caret", "tilde", "range", "hyphen", "compare"}
+
+// semverBumpBits is the entropy a range's upper bound adds.
+const semverBumpBits = 3.9 // log2(3 * 5)
+
+// appendSemverRange appends a satisfiable semver constraint of the named form.
+func appendSemverRange(r Source, out []byte, form []byte) []byte {
+	name := strings.ToLower(string(bytes.TrimSpace(form)))
+	if !slices.Contains(semverRangeForms, name) {
+		name = pick(r, semverRangeForms)
+	}
+	lower := [3]int{intN(r, 20), intN(r, 50), intN(r, 100)}
+
+	switch name {
+	case "caret":
+		out = appendSemverCore(append(out, '^'), lower)
+	case "tilde":
+		out = appendSemverCore(append(out, '~'), lower)
+	case "range", "hyphen":
+		upper := semverBump(r, lower)
+		if name == "range" {
+			out = append(out, ">="...)
+			out = appendSemverCore(out, lower)
+			out = append(out, " <"...)
+		} else {
+			out = appendSemverCore(out, lower)
+			out = append(out, " - "...)
+		}
+		out = appendSemverCore(out, upper)
+	default:
+		op := pick(r, []string{">", ">=", "<", "<="})
+		if op == "<" && lower == [3]int{} {
+			// <0.0.0 matches nothing.
+			lower[2] = 1
+		}
+		out = append(out, op...)
+		out = appendSemverCore(out, lower)
+	}
+	return out
+}
+
+// semverBump returns the next major, minor or patch release after v.
+func semverBump(r Source, v [3]int) [3]int {
+	part := intN(r, len(v))
+	v[part] += intRange(r, 1, 5)
+	for i := part + 1; i < len(v); i++ {
+		v[i] = 0
+	}
+	return v
+}
+
+func appendSemverCore(out []byte, v [3]int) []byte {
+	for i, part := range v {
+		if i > 0 {
+			out = append(out, '.')
+		}
+		out = strconv.AppendInt(out, int64(part), 10)
+	}
+	return out
+}
+
+var (
+	filenameChars      = CharsList("abcdefghijklmnopqrstuvwxyz0123456789")
+	filenameExtensions = []string{"txt", "pdf", "docx", "xlsx", "png", "jpg", "gif", "csv", "json", "log", "zip"}
+)
+
+// generateFilename emits a lowercase base name plus an extension.
+func generateFilename(r Source, length int, extensions []byte) []byte {
+	out := []byte(randString(r, length, filenameChars))
+	out = append(out, '.')
+
+	var candidates [][]byte
+	for _, ext := range bytes.Split(extensions, []byte(",")) {
+		ext = bytes.TrimLeft(bytes.TrimSpace(ext), ".")
+		if len(ext) > 0 && isSafeFilename(ext) {
+			candidates = append(candidates, ext)
+		}
+	}
+	if len(candidates) == 0 {
+		return append(out, pick(r, filenameExtensions)...)
+	}
+	return append(out, pick(r, candidates)...)
+}
+
+func isSafeFilename(name []byte) bool {
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// generatePath emits a path of segments random segments.
+func generatePath(r Source, segments int, modifiers []byte) []byte {
+	var windows, relative, trailing bool
+	for _, mod := range bytes.Split(modifiers, []byte(",")) {
+		switch {
+		case bytes.EqualFold(mod, []byte("windows")):
+			windows = true
+		case bytes.EqualFold(mod, []byte("relative")):
+			relative = true
+		case bytes.EqualFold(mod, []byte("trailing")):
+			trailing = true
+		}
+	}
+
+	separator, root := byte('/'), "/"
+	if windows {
+		separator, root = '\\', "C:\\"
+	}
+	if relative {
+		root = ""
+	}
+
+	out := []byte(root)
+	for i := 0; i < segments; i++ {
+		if i > 0 {
+			out = append(out, separator)
+		}
+		out = append(out, randString(r, intRange(r, 3, 10), filenameChars)...)
+	}
+	if trailing && segments > 0 {
+		out = append(out, separator)
+	}
+	return out
+}
+
+// maxPID is the largest PID Linux hands out (pid_max on 64-bit systems).
+const maxPID = 4194304
+
+// randomPID returns a random PID, or the current one for "self".
+func randomPID(r Source, modifier []byte) int64 {
+	if bytes.EqualFold(modifier, []byte("self")) {
+		return int64(os.Getpid())
+	}
+	return int64(intRange(r, 1, maxPID))
+}
+
+var (
+	defaultTimeRangeStart = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultTimeRangeEnd   = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	// rfc3339Offsets are real-world UTC offsets in minutes. Zero is formatted
+	// as the 'Z' suffix.
+	rfc3339Offsets = []int{-480, -420, -300, -240, -180, 0, 0, 60, 120, 180, 330, 480, 540, 600, 765}
+)
+
+func (e *FastEngine) randomTime() time.Time {
+	r := e.src
+	span := e.timeRangeEnd.Unix() - e.timeRangeStart.Unix()
+	if span <= 0 {
+		return e.timeRangeStart
+	}
+	return time.Unix(e.timeRangeStart.Unix()+int64(intRange(r, 0, int(span))), 0)
+}
+
+func (e *FastEngine) generateRFC3339() []byte {
+	r := e.src
+	offset := pick(r, rfc3339Offsets)
+	zone := time.UTC
+	if offset != 0 {
+		zone = time.FixedZone("", offset*60)
+	}
+	return e.randomTime().In(zone).AppendFormat(nil, time.RFC3339)
+}
+
+// generateLocale picks a BCP 47 tag, optionally language or region only.
+func generateLocale(r Source, modifier []byte) string {
+	switch {
+	case bytes.EqualFold(modifier, []byte("lang")):
+		return pick(r, languageLocales)
+	case bytes.EqualFold(modifier, []byte("region")):
+		return pick(r, regionLocales)
+	default:
+		return pick(r, Locales)
+	}
+}
+
+var (
+	identStartChars = append(CharsList("_"), CharsAlphabet...)
+	identChars      = append(CharsList("_-"), CharsAlphabetDigits...)
+)
+
+// generateIdent emits an identifier valid in CSS.
+func generateIdent(r Source, length int) []byte {
+	out := make([]byte, 0, length)
+	out = append(out, pick(r, identStartChars))
+	if length > 1 {
+		out = append(out, randString(r, length-1, identChars)...)
+	}
+	return out
+}
+
+// generateQuery emits an encoded query string of pairs distinct keys.
+func generateQuery(r Source, pairs int) string {
+	maxKeyLen, keySpace := 3, 26+26*26+26*26*26
+	for keySpace/2 < pairs {
+		maxKeyLen++
+		keySpace = keySpace*26 + 26
+	}
+	values := make(url.Values, pairs)
+	for len(values) < pairs {
+		key := randString(r, intRange(r, 1, maxKeyLen), CharsAlphabetLower)
+		if _, dup := values[key]; !dup {
+			values.Set(key, randString(r, intRange(r, 1, 8), CharsAll))
+		}
+	}
+	return values.Encode()
+}
+
+// maxOIDArc bounds the arcs of OID after the first two.
+const maxOIDArc = 99999
+
+// generateOID emits a dotted-decimal object identifier of arcs arcs.
+func generateOID(r Source, arcs int) []byte {
+	arcs = max(arcs, 2)
+	first := intN(r, 3)
+	out := strconv.AppendInt(make([]byte, 0, arcs*6), int64(first), 10)
+	second := maxOIDArc
+	if first < 2 {
+		second = 39
+	}
+	out = append(out, '.')
+	out = strconv.AppendInt(out, int64(intRange(r, 0, second)), 10)
+	for i := 2; i < arcs; i++ {
+		out = append(out, '.')
+		out = strconv.AppendInt(out, int64(intRange(r, 0, maxOIDArc)), 10)
+	}
+	return out
+}
+
+// baseDigits are the digits of BASEN; a base uses its first base digits.
+const baseDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// parseBaseN parses the "BASE[;MAX]" argument of BASEN.
+func parseBaseN(arg []byte) (base, maxValue int, hasMax bool) {
+	basePart, maxPart, _ := bytes.Cut(arg, []byte{sepTag})
+	base, err := strconv.Atoi(string(basePart))
+	if err != nil || base < 2 || base > len(baseDigits) {
+		base = 10
+	}
+	if len(maxPart) == 0 {
+		return base, 0, false
+	}
+	v, err := strconv.ParseInt(string(maxPart), base, 0)
+	if err != nil || v < 0 || v == math.MaxInt {
+		return base, 0, false
+	}
+	return base, int(v), true
+}
+
+// appendBaseN appends length digits, or a value up to max, in base arg.
+func appendBaseN(r Source, dst []byte, length int, arg []byte) []byte {
+	base, maxValue, hasMax := parseBaseN(arg)
+	if hasMax {
+		return strconv.AppendInt(dst, int64(intRange(r, 0, maxValue)), base)
+	}
+	return appendRandString(r, dst, length, CharsList(baseDigits[:base]))
+}
+
+// bracketPairs maps each opening bracket BRACKETS knows to its closer.
+var bracketPairs = map[byte]byte{'(': ')', '[': ']', '{': '}', '<': '>'}
+
+// bracketNames lets BRACKETS name brackets that cannot appear in a tag.
+var bracketNames = map[string]byte{"round": '(', "square": '[', "curly": '{', "angle": '<'}
+
+// parseBracketTypes returns the opening brackets arg selects.
+func parseBracketTypes(arg []byte) []byte {
+	var openers []byte
+	add := func(open byte) {
+		if bytes.IndexByte(openers, open) == -1 {
+			openers = append(openers, open)
+		}
+	}
+	for _, item := range bytes.Split(arg, []byte(",")) {
+		if open, ok := bracketNames[strings.ToLower(string(item))]; ok {
+			add(open)
+			continue
+		}
+		for _, c := range item {
+			for open, closer := range bracketPairs {
+				if c == open || c == closer {
+					add(open)
+				}
+			}
+		}
+	}
+	if len(openers) == 0 {
+		return []byte("([{")
+	}
+	return openers
+}
+
+// generateBrackets emits a balanced sequence of about length brackets.
+func generateBrackets(r Source, length int, arg []byte) []byte {
+	openers := parseBracketTypes(arg)
+	length += length % 2
+	out := make([]byte, 0, length)
+	stack := make([]byte, 0, length/2)
+	for len(out) < length {
+		remaining := length - len(out)
+		if len(stack) == 0 || len(stack) < remaining && randBool(r) {
+			open := pick(r, openers)
+			stack = append(stack, open)
+			out = append(out, open)
+			continue
+		}
+		out = append(out, bracketPairs[stack[len(stack)-1]])
+		stack = stack[:len(stack)-1]
+	}
+	return out
+}
+
+// generateISBN emits an ISBN-13, or an ISBN-10 for format "10".
+func generateISBN(r Source, format []byte) []byte {
+	if string(format) == "10" {
+		out := appendRandString(r, make([]byte, 0, 10), 9, CharsDigits)
+		sum := 0
+		for i, c := range out {
+			sum += (10 - i) * int(c-'0')
+		}
+		check := (11 - sum%11) % 11
+		if check == 10 {
+			return append(out, 'X')
+		}
+		return append(out, byte('0'+check))
+	}
+
+	out := append(make([]byte, 0, 13), "97"...)
+	out = append(out, byte('8'+intN(r, 2)))
+	out = appendRandString(r, out, 9, CharsDigits)
+	sum := 0
+	for i, c := range out {
+		sum += (1 + 2*(i%2)) * int(c-'0')
+	}
+	return append(out, byte('0'+(10-sum%10)%10))
+}
+
+// shortIDAlphabet is the base62 alphabet of SHORTID, in code point order.
+const shortIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// generateShortID emits length base62 characters and a check character.
+func generateShortID(r Source, length int) []byte {
+	out := appendRandString(r, make([]byte, 0, length+1), length, CharsList(shortIDAlphabet))
+	return append(out, shortIDAlphabet[luhnBase62(out, 2)])
+}
+
+// VerifyShortID reports whether id is a SHORTID: at least two base62
+// characters whose last one is the check character of the rest. Any single
+// changed character, and most swaps of adjacent ones, are detected.
+func VerifyShortID(id string) bool {
+	if len(id) < 2 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(shortIDAlphabet, id[i]) == -1 {
+			return false
+		}
+	}
+	return luhnBase62([]byte(id), 1) == 0
+}
+
+// luhnBase62 runs Luhn mod 62 over b from the right, starting at factor.
+func luhnBase62(b []byte, factor int) int {
+	const n = len(shortIDAlphabet)
+	sum := 0
+	for i := len(b) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(shortIDAlphabet, b[i])
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return (n - sum%n) % n
+}
+
+// geoBox is a latitude/longitude bounding box in degrees.
+type geoBox struct {
+	minLat, minLon, maxLat, maxLon float64
+}
+
+var globalGeoBox = geoBox{-90, -180, 90, 180}
+
+// parseCountryBounds reads country bounding boxes keyed by code and name.
+func parseCountryBounds(data string) map[string]geoBox {
+	boxes := make(map[string]geoBox)
+	for _, line := range splitLines(data) {
+		fields := strings.Fields(line)
+		if strings.HasPrefix(line, "#") || len(fields) != 6 {
+			continue
+		}
+		var coords [4]float64
+		var err error
+		for i := range coords {
+			if coords[i], err = strconv.ParseFloat(fields[2+i], 64); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			continue
+		}
+		box := geoBox{coords[0], coords[1], coords[2], coords[3]}
+		boxes[strings.ToLower(fields[0])] = box
+		boxes[strings.ToLower(fields[1])] = box
+	}
+	return boxes
+}
+
+// geoBoxFor looks a country up by code or name, or returns the globe.
+func geoBoxFor(country []byte) geoBox {
+	key := strings.ReplaceAll(strings.ToLower(string(bytes.TrimSpace(country))), " ", "_")
+	if box, ok := countryBoxes[key]; ok {
+		return box
+	}
+	return globalGeoBox
+}
+
+// generateGeo emits "lat,lon" inside the bounding box of country.
+func generateGeo(r Source, country []byte) []byte {
+	box := geoBoxFor(country)
+	lat := box.minLat + randFloat64(r)*(box.maxLat-box.minLat)
+	lon := box.minLon + randFloat64(r)*(box.maxLon-box.minLon)
+	out := strconv.AppendFloat(nil, lat, 'f', 6, 64)
+	out = append(out, ',')
+	return strconv.AppendFloat(out, lon, 'f', 6, 64)
+}
+
+// hashPickArgs splits the "key;a,b,c" argument of HASHPICK.
+func hashPickArgs(arg []byte) (key []byte, choices [][]byte) {
+	key, list, _ := bytes.Cut(arg, []byte{sepTag})
+	if len(list) == 0 {
+		return key, nil
+	}
+	return key, bytes.Split(list, []byte(","))
+}
+
+// hashPick picks one of choices by the FNV-1a hash of key.
+func hashPick(key []byte, choices [][]byte) []byte {
+	if len(choices) == 0 {
+		return nil
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	return choices[h.Sum64()%uint64(len(choices))]
+}
+
+// cronFields are the bounds of the five cron fields, days capped at 28.
+var cronFields = [][2]int{{0, 59}, {0, 23}, {1, 28}, {1, 12}, {0, 6}}
+
+// generateCron emits a cron expression, with seconds for "seconds".
+func generateCron(r Source, modifier []byte) []byte {
+	fields := cronFields
+	if bytes.EqualFold(modifier, []byte("seconds")) {
+		fields = append([][2]int{{0, 59}}, cronFields...)
+	}
+	var out []byte
+	for i, field := range fields {
+		if i > 0 {
+			out = append(out, ' ')
+		}
+		out = appendCronField(r, out, field[0], field[1])
+	}
+	return out
+}
+
+func appendCronField(r Source, out []byte, lo, hi int) []byte {
+	switch intN(r, 5) {
+	case 0:
+		return append(out, '*')
+	case 1:
+		return strconv.AppendInt(out, int64(intRange(r, lo, hi)), 10)
+	case 2:
+		return appendCronRange(r, out, lo, hi)
+	case 3:
+		out = append(out, "*/"...)
+		return strconv.AppendInt(out, int64(intRange(r, 2, (hi-lo+1)/2)), 10)
+	default:
+		out = appendCronRange(r, out, lo, hi)
+		out = append(out, '/')
+		return strconv.AppendInt(out, int64(intRange(r, 2, 5)), 10)
+	}
+}
+
+func appendCronRange(r Source, out []byte, lo, hi int) []byte {
+	start := intRange(r, lo, hi-1)
+	out = strconv.AppendInt(out, int64(start), 10)
+	out = append(out, '-')
+	return strconv.AppendInt(out, int64(intRange(r, start+1, hi)), 10)
+}
+
+// protoWireTypes are the non-deprecated protobuf wire types.
+var protoWireTypes = []uint64{0, 1, 2, 5}
+
+const (
+	maxProtoField      = 1<<29 - 1
+	maxProtoBytesField = 16
+)
+
+// generateProtoWire emits fields well-formed protobuf wire-format entries.
+func generateProtoWire(r Source, fields int) []byte {
+	var out []byte
+	for i := 0; i < fields; i++ {
+		field := intRange(r, 1, maxProtoField)
+		for field >= 19000 && field <= 19999 {
+			field = intRange(r, 1, maxProtoField)
+		}
+		wireType := pick(r, protoWireTypes)
+		out = binary.AppendUvarint(out, uint64(field)<<3|wireType)
+		switch wireType {
+		case 0:
+			var b [8]byte
+			readFull(r, b[:])
+			out = binary.AppendUvarint(out, binary.LittleEndian.Uint64(b[:])>>intN(r, 64))
+		case 1:
+			out = append(out, randBytes(r, 8)...)
+		case 2:
+			n := intRange(r, 0, maxProtoBytesField)
+			out = binary.AppendUvarint(out, uint64(n))
+			out = append(out, randBytes(r, n)...)
+		case 5:
+			out = append(out, randBytes(r, 4)...)
+		}
+	}
+	return out
+}
+
+var (
+	// defaultMethodWeights roughly follow the share of each method in real
+	// traffic.
+	defaultMethodWeights = map[string]float64{
+		"GET": 60, "POST": 20, "PUT": 5, "DELETE": 4, "PATCH": 3,
+		"HEAD": 4, "OPTIONS": 3, "CONNECT": 0.5, "TRACE": 0.5,
+	}
+	methodClasses = map[string][]string{
+		"safe":       {"GET", "HEAD", "OPTIONS"},
+		"idempotent": {"GET", "HEAD", "OPTIONS", "PUT", "DELETE", "TRACE"},
+		"unsafe":     {"POST", "PUT", "DELETE", "PATCH"},
+	}
+)
+
+// methodChoices returns the methods and weights METHOD picks from for class.
+func (e *FastEngine) methodChoices(class []byte) ([]string, []float64) {
+	var methods []string
+	for _, method := range methodClasses[strings.ToLower(string(class))] {
+		if e.methodWeights[method] > 0 {
+			methods = append(methods, method)
+		}
+	}
+	if len(methods) == 0 {
+		for method, weight := range e.methodWeights {
+			if weight > 0 {
+				methods = append(methods, method)
+			}
+		}
+		slices.Sort(methods)
+	}
+	weights := make([]float64, len(methods))
+	for i, method := range methods {
+		weights[i] = e.methodWeights[method]
+	}
+	return methods, weights
+}
+
+func (e *FastEngine) generateMethod(class []byte) string {
+	methods, weights := e.methodChoices(class)
+	return weightedChoice(methods, weights, func() float64 { return randFloat64(e.src) })
+}
+
+// parsePalettes reads "name #rrggbb ..." lines into palettes.
+func parsePalettes(data string) map[string][]string {
+	palettes := make(map[string][]string)
+	for _, line := range splitLines(data) {
+		fields := strings.Fields(line)
+		if strings.HasPrefix(line, "#") || len(fields) < 2 {
+			continue
+		}
+		var colors []string
+		for _, field := range fields[1:] {
+			if color, ok := normalizeHexColor(field); ok {
+				colors = append(colors, color)
+			}
+		}
+		if len(colors) > 0 {
+			palettes[strings.ToLower(fields[0])] = colors
+		}
+	}
+	return palettes
+}
+
+// normalizeHexColor turns a hex color into lowercase "#rrggbb".
+func normalizeHexColor(color string) (string, bool) {
+	digits := strings.TrimPrefix(strings.TrimSpace(color), "#")
+	if len(digits) != 3 && len(digits) != 6 {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(digits, 16, 32); err != nil {
+		return "", false
+	}
+	digits = strings.ToLower(digits)
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	return "#" + digits, true
+}
+
+// palette looks name up among the engine's palettes, then the embedded ones.
+func (e *FastEngine) palette(name []byte) []string {
+	key := strings.ToLower(string(bytes.TrimSpace(name)))
+	if colors, ok := e.palettes[key]; ok {
+		return colors
+	}
+	return colorPalettes[key]
+}
+
+// appendColor appends a color from the named palette, or a random one.
+func (e *FastEngine) appendColor(r Source, dst []byte, name []byte) []byte {
+	if colors := e.palette(name); len(colors) > 0 {
+		return append(dst, pick(r, colors)...)
+	}
+	var rgb [3]byte
+	readFull(r, rgb[:])
+	dst = append(dst, '#')
+	return hex.AppendEncode(dst, rgb[:])
+}
+
+// fileMagics are the signatures MAGIC emits, keyed by file type.
+var fileMagics = map[string][]byte{
+	"png":  {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'},
+	"jpeg": {0xff, 0xd8, 0xff},
+	"jpg":  {0xff, 0xd8, 0xff},
+	"gif":  []byte("GIF89a"),
+	"pdf":  []byte("%PDF-"),
+	"zip":  {'P', 'K', 0x03, 0x04},
+	"gzip": {0x1f, 0x8b},
+	"bmp":  []byte("BM"),
+}
+
+// fileMagic returns the signature of the named file type, or nil.
+func fileMagic(fileType []byte) []byte {
+	return fileMagics[strings.ToLower(string(bytes.TrimSpace(fileType)))]
+}
+
+var jsonScalarTypes = []string{"string", "number", "bool", "null"}
+
+// appendJSONValue appends a random JSON value of the given type.
+func appendJSONValue(r Source, out []byte, typ string) []byte {
+	switch typ {
+	case "number":
+		if randBool(r) {
+			return strconv.AppendInt(out, int64(intRange(r, -1000000, 1000000)), 10)
+		}
+		return strconv.AppendFloat(out, (randFloat64(r)-0.5)*2000, 'f', -1, 64)
+	case "bool":
+		return strconv.AppendBool(out, randBool(r))
+	case "null":
+		return append(out, "null"...)
+	case "array":
+		out = append(out, '[')
+		for i := range intN(r, 6) {
+			if i > 0 {
+				out = append(out, ',')
+			}
+			out = appendJSONValue(r, out, pick(r, jsonScalarTypes))
+		}
+		return append(out, ']')
+	default:
+		return strconv.AppendQuote(out, randString(r, intRange(r, 1, 12), CharsAll))
+	}
+}
+
+var yamlScalarTypes = []string{"int", "float", "bool", "string"}
+
+// yamlLookalikes are strings YAML would not read as strings when unquoted.
+var yamlLookalikes = []string{
+	"yes", "No", "on", "OFF", "y", "n", "true", "False", "null", "Null", "~", "",
+	"123", "-7", "0x1F", "0o17", "1e3", "3.14", ".inf", "-.Inf", ".nan", "1_000",
+}
+
+// yamlStringChars mixes in the indicators that make plain scalars ambiguous.
+var yamlStringChars = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 _-.:#'\"&*!|>%@`,[]{}")
+
+// appendYAMLValue appends a YAML scalar that reads back as the given type.
+func appendYAMLValue(r Source, out []byte, typ string) []byte {
+	if !slices.Contains(yamlScalarTypes, typ) {
+		typ = pick(r, yamlScalarTypes)
+	}
+	switch typ {
+	case "int":
+		return strconv.AppendInt(out, int64(intRange(r, -1000000, 1000000)), 10)
+	case "float":
+		start := len(out)
+		out = strconv.AppendFloat(out, (randFloat64(r)-0.5)*2000, 'f', -1, 64)
+		if !bytes.ContainsRune(out[start:], '.') {
+			out = append(out, ".0"...)
+		}
+		return out
+	case "bool":
+		return strconv.AppendBool(out, randBool(r))
+	}
+	var s string
+	if intN(r, 4) == 0 {
+		s = pick(r, yamlLookalikes)
+	} else {
+		s = randString(r, intRange(r, 1, 12), yamlStringChars)
+	}
+	if yamlPlainSafe(s) {
+		return append(out, s...)
+	}
+	return strconv.AppendQuote(out, s)
+}
+
+func yamlPlainSafe(s string) bool {
+	if s == "" || !bytes.ContainsRune(CharsAlphabet, rune(s[0])) {
+		return false
+	}
+	if strings.Trim(s, string(CharsAlphabetDigits)+"_") != "" {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "on", "off", "true", "false", "null":
+		return false
+	}
+	return true
+}
+
+const (
+	defaultXMLDepth = 2
+	maxXMLDepth     = 5
+	// xmlAttempts is how often XML regenerates a snippet that does not parse
+	// before settling for an empty element.
+	xmlAttempts = 4
+)
+
+var (
+	xmlNameStartChars = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_")
+	xmlNameChars      = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-.")
+	// xmlTextChars includes the characters that must be escaped in text and
+	// attribute values.
+	xmlTextChars = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 .,:;!?-_<>&\"'")
+)
+
+// generateXML emits a well-formed XML element nested up to the depth in arg.
+func generateXML(r Source, arg []byte) []byte {
+	depth := defaultXMLDepth
+	if d, err := strconv.Atoi(string(bytes.TrimSpace(arg))); err == nil {
+		depth = min(max(d, 1), maxXMLDepth)
+	}
+	for attempt := 0; attempt < xmlAttempts; attempt++ {
+		out := appendXMLElement(r, nil, depth)
+		if xmlWellFormed(out) {
+			return out
+		}
+	}
+	return []byte("<a/>")
+}
+
+// appendXMLElement appends an element with attributes and children.
+func appendXMLElement(r Source, dst []byte, depth int) []byte {
+	dst = append(dst, '<')
+	start := len(dst)
+	dst = appendXMLName(r, dst)
+	name := bytes.Clone(dst[start:])
+
+	var attrs [][]byte
+	for n := intN(r, 3); n > 0; n-- {
+		attr := appendXMLName(r, nil)
+		if slices.ContainsFunc(attrs, func(a []byte) bool { return bytes.Equal(a, attr) }) {
+			continue
+		}
+		attrs = append(attrs, attr)
+		dst = append(dst, ' ')
+		dst = append(dst, attr...)
+		dst = append(dst, `="`...)
+		dst = appendXMLEscaped(dst, randString(r, intRange(r, 1, 8), xmlTextChars))
+		dst = append(dst, '"')
+	}
+
+	children := 0
+	if depth > 1 {
+		children = intN(r, 4)
+	}
+	if children == 0 && intN(r, 4) == 0 {
+		return append(dst, "/>"...)
+	}
+	dst = append(dst, '>')
+	dst = appendXMLEscaped(dst, randString(r, intRange(r, 1, 12), xmlTextChars))
+	for ; children > 0; children-- {
+		dst = appendXMLElement(r, dst, depth-1)
+		if randBool(r) {
+			dst = appendXMLEscaped(dst, randString(r, intRange(r, 1, 12), xmlTextChars))
+		}
+	}
+	dst = append(dst, "</"...)
+	dst = append(dst, name...)
+	return append(dst, '>')
+}
+
+// appendXMLName appends an NCName that does not start with "xml".
+func appendXMLName(r Source, dst []byte) []byte {
+	start := len(dst)
+	dst = append(dst, pick(r, xmlNameStartChars))
+	if n := intN(r, 8); n > 0 {
+		dst = appendRandString(r, dst, n, xmlNameChars)
+	}
+	if len(dst)-start >= 3 && strings.EqualFold(string(dst[start:start+3]), "xml") {
+		dst = slices.Insert(dst, start, '_')
+	}
+	return dst
+}
+
+func appendXMLEscaped(dst []byte, s string) []byte {
+	buffer := bytes.NewBuffer(dst)
+	_ = xml.EscapeText(buffer, []byte(s))
+	return buffer.Bytes()
+}
+
+// xmlWellFormed reports whether b parses as XML with a single root element.
+func xmlWellFormed(b []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return depth == 0 && roots == 1
+		}
+		if err != nil {
+			return false
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 {
+				return false
+			}
+		}
+	}
+}
+
+// generateRule repeats the first rune of char length times.
+func generateRule(length int, char []byte) []byte {
+	r, size := utf8.DecodeRune(char)
+	if size == 0 || r == utf8.RuneError {
+		r = '-'
+	}
+	return bytes.Repeat(utf8.AppendRune(nil, r), length)
+}
+
+// parseOUI parses a vendor prefix such as 00:1A:2B, or returns nil.
+func parseOUI(s []byte) []byte {
+	if len(s) != 8 || s[2] != s[5] || (s[2] != ':' && s[2] != '-') {
+		return nil
+	}
+	oui := make([]byte, 3)
+	for i := range oui {
+		if _, err := hex.Decode(oui[i:i+1], s[i*3:i*3+2]); err != nil {
+			return nil
+		}
+	}
+	return oui
+}
+
+var flagFormats = map[string][2]string{
+	"truefalse": {"true", "false"},
+	"onoff":     {"on", "off"},
+	"yesno":     {"yes", "no"},
+	"10":        {"1", "0"},
+}
+
+// generateFlag emits the true or false token of the format in arg.
+func generateFlag(r Source, arg []byte) string {
+	format, bias, _ := bytes.Cut(arg, []byte{sepTag})
+	tokens, ok := flagFormats[strings.ToLower(string(format))]
+	if !ok {
+		tokens = flagFormats["truefalse"]
+	}
+	pct, err := strconv.Atoi(string(bias))
+	if err != nil {
+		pct = 50
+	}
+	if intN(r, 100) < pct {
+		return tokens[0]
+	}
+	return tokens[1]
+}
+
+var defaultInjectSequences = []string{
+	"\r\nX-Injected: {RAND;8;ABR}",
+	"\nX-Injected: {RAND;8;ABR}",
+	"\r\n\r\n",
+	"\r",
+	"%0d%0aX-Injected: {RAND;8;ABR}",
+	";",
+}
+
+// headerValueChars cannot end a header line.
+var headerValueChars = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.,;=/+")
+
+// generateHeaders emits count header lines with distinct names.
+func (e *FastEngine) generateHeaders(count int, modifier []byte, state *callState) []byte {
+	r := e.src
+	count = min(count, len(HeaderNames))
+	names := slices.Clone(HeaderNames)
+	inject := -1
+	if bytes.EqualFold(modifier, []byte("inject")) {
+		inject = intN(r, count)
+	}
+
+	var out []byte
+	for i := 0; i < count; i++ {
+		j := intRange(r, i, len(names)-1)
+		names[i], names[j] = names[j], names[i]
+		value := []byte(randString(r, intRange(r, 1, 32), headerValueChars))
+		if i == inject {
+			value = e.generateCRLFInject(value, state)
+		}
+		out = append(out, names[i]...)
+		out = append(out, ": "...)
+		out = append(out, value...)
+		out = append(out, "\r\n"...)
+	}
+	return out
+}
+
+// csvRowsArgs splits the "COL,COL,...[;header]" argument of CSVROWS.
+func csvRowsArgs(arg []byte) (columns [][]byte, header bool) {
+	list, modifier, _ := bytes.Cut(arg, []byte{sepTag})
+	for _, column := range bytes.Split(list, []byte(",")) {
+		if column = bytes.TrimSpace(column); len(column) > 0 {
+			columns = append(columns, column)
+		}
+	}
+	return columns, bytes.EqualFold(bytes.TrimSpace(modifier), []byte("header"))
+}
+
+// generateCSVRows writes rows CSV records; it fails without columns.
+func (e *FastEngine) generateCSVRows(rows int, arg []byte, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState) bool {
+	columns, header := csvRowsArgs(arg)
+	if len(columns) == 0 {
+		return false
+	}
+	if header {
+		for i, column := range columns {
+			if i > 0 {
+				_ = buffer.WriteByte(',')
+			}
+			mark := len(buffer.B)
+			_, _ = buffer.Write(column)
+			quoteCSVField(buffer, mark)
+		}
+	}
+
+	for row := 0; row < rows; row++ {
+		if row > 0 || header {
+			_ = buffer.WriteByte('\n')
+		}
+		for i, column := range columns {
+			if i > 0 {
+				_ = buffer.WriteByte(',')
+			}
+			keyword := e.resolveAlias(strings.ToUpper(string(column)))
+			mark := len(buffer.B)
+			length := e.lengthBoundsFor([]byte(keyword)).clamp(e.defaultLength)
+			if e.preserveOnError {
+				if !e.tryGenerateKeyword([]byte(keyword), keyword, nil, length, buffer, encoding, state) {
+					buffer.B = buffer.B[:mark]
+				}
+			} else {
+				e.generateKeyword([]byte(keyword), keyword, nil, length, buffer, encoding, state)
+			}
+			quoteCSVField(buffer, mark)
+		}
+	}
+	return true
+}
+
+// parseStep parses the "start;stop;step" argument of STEP.
+func parseStep(arg []byte) (start, step int64, count uint64, ok bool) {
+	var values [3]int64
+	rest, more := arg, true
+	for i := range values {
+		if !more {
+			return 0, 0, 0, false
+		}
+		var part []byte
+		part, rest, more = bytes.Cut(rest, []byte{sepTag})
+		v, err := strconv.ParseInt(string(bytes.TrimSpace(part)), 10, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		values[i] = v
+	}
+	if more {
+		return 0, 0, 0, false
+	}
+	start, stop, step := values[0], values[1], values[2]
+	var steps uint64
+	switch {
+	case step > 0 && stop >= start:
+		// The difference may overflow int64 but always fits in uint64.
+		steps = uint64(stop-start) / uint64(step)
+	case step < 0 && stop <= start:
+		steps = uint64(start-stop) / (^uint64(step) + 1)
+	default:
+		return 0, 0, 0, false
+	}
+	if steps == math.MaxUint64 {
+		return start, step, steps, true
+	}
+	return start, step, steps + 1, true
+}
+
+// stepValue returns a random element of the progression arg describes.
+func stepValue(r Source, arg []byte) (int64, bool) {
+	start, step, count, ok := parseStep(arg)
+	if !ok {
+		return 0, false
+	}
+	if count <= math.MaxInt {
+		return start + int64(intN(r, int(count)))*step, true
+	}
+	// Beyond MaxInt, draw 64-bit indices, rejecting those past the end unless
+	// the count is saturated and every index is valid. Overflowing products
+	// wrap around to the right value.
+	var b [8]byte
+	for {
+		readFull(r, b[:])
+		if index := binary.LittleEndian.Uint64(b[:]); count == math.MaxUint64 || index < count {
+			return start + int64(index)*step, true
+		}
+	}
+}
+
+const (
+	defaultLatencyMedian = 100.0
+	// latencyMaxSigma bounds the log-space spread so exp never overflows.
+	latencyMaxSigma = 10.0
+)
+
+// latencyUnit scales and rounds a latency in milliseconds.
+type latencyUnit struct {
+	scale    float64
+	decimals int
+}
+
+var latencyUnits = map[string]latencyUnit{
+	"us": {1000, 0},
+	"ms": {1, 1},
+	"s":  {0.001, 4},
+}
+
+// parseLatency parses the "median;sigma;unit" argument of LATENCY.
+func parseLatency(arg []byte) (median, logSigma float64, unit latencyUnit, ok bool) {
+	parts := bytes.Split(arg, []byte{sepTag})
+	if len(parts) > 3 {
+		return 0, 0, unit, false
+	}
+	values := [2]float64{defaultLatencyMedian, -1}
+	for i := 0; i < len(parts) && i < len(values); i++ {
+		part := bytes.TrimSpace(parts[i])
+		if len(part) == 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(string(part), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			return 0, 0, unit, false
+		}
+		values[i] = v
+	}
+	median, sigma := values[0], values[1]
+	if median <= 0 {
+		return 0, 0, unit, false
+	}
+	if sigma < 0 {
+		sigma = median / 2
+	}
+	unit = latencyUnits["ms"]
+	if len(parts) == 3 {
+		if unit, ok = latencyUnits[strings.ToLower(string(bytes.TrimSpace(parts[2])))]; !ok {
+			return 0, 0, unit, false
+		}
+	}
+	return median, min(math.Log1p(sigma/median), latencyMaxSigma), unit, true
+}
+
+// appendLatency appends a log-normal latency; a malformed arg is not ok.
+func appendLatency(r Source, dst, arg []byte) ([]byte, bool) {
+	median, logSigma, unit, ok := parseLatency(arg)
+	if !ok {
+		return dst, false
+	}
+	v := median * math.Exp(logSigma*randNormFloat64(r)) * unit.scale
+	return strconv.AppendFloat(dst, v, 'f', unit.decimals, 64), true
+}
+
+var (
+	mxHostPrefixes = []string{"mail", "mx", "mx1", "mx2", "smtp"}
+	hostnameTLDs   = []string{"com", "net", "org", "io", "dev"}
+	txtChars       = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 =-_.:")
+)
+
+// appendHostname appends a hostname starting with one of prefixes.
+func appendHostname(r Source, dst []byte, prefixes []string) []byte {
+	dst = append(dst, pick(r, prefixes)...)
+	dst = append(dst, '.')
+	dst = appendDomainLabels(r, dst, 1)
+	dst = append(dst, '.')
+	return append(dst, pick(r, hostnameTLDs)...)
+}
+
+// appendDomainLabels appends n dot-separated lowercase labels.
+func appendDomainLabels(r Source, dst []byte, n int) []byte {
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dst = append(dst, '.')
+		}
+		dst = appendRandString(r, dst, intRange(r, 3, 10), CharsAlphabetLower)
+	}
+	return dst
+}
+
+// appendDNSValue appends a value for a DNS record of the given type.
+func appendDNSValue(r Source, dst []byte, length int, recordType []byte) []byte {
+	switch strings.ToUpper(string(bytes.TrimSpace(recordType))) {
+	case "AAAA":
+		return append(dst, net.IP(randBytes(r, net.IPv6len)).String()...)
+	case "MX":
+		dst = strconv.AppendInt(dst, int64(10*intN(r, 6)), 10)
+		dst = append(dst, ' ')
+		return appendHostname(r, dst, mxHostPrefixes)
+	case "TXT":
+		dst = append(dst, '"')
+		dst = appendRandString(r, dst, length, txtChars)
+		return append(dst, '"')
+	}
+	return append(dst, formatIPv4(randBytes(r, net.IPv4len), nil)...)
+}
+
+// jsonPointerKeyChars includes '/' and '~' to exercise the escapes.
+var jsonPointerKeyChars = CharsList("abcdefghijklmnopqrstuvwxyz0123456789_-~/")
+
+const maxJSONPointerIndex = 99
+
+// generateJSONPointer emits an RFC 6901 JSON Pointer of length segments.
+func generateJSONPointer(r Source, length int) []byte {
+	var out []byte
+	for i := 0; i < length; i++ {
+		out = append(out, '/')
+		if intN(r, 4) == 0 {
+			out = strconv.AppendInt(out, int64(intN(r, maxJSONPointerIndex+1)), 10)
+			continue
+		}
+		for n := intRange(r, 1, 8); n > 0; n-- {
+			switch c := pick(r, jsonPointerKeyChars); c {
+			case '~':
+				out = append(out, "~0"...)
+			case '/':
+				out = append(out, "~1"...)
+			default:
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// quotedChars includes the characters QUOTED has to escape.
+var quotedChars = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 '\"\\$`")
+
+// appendQuoted appends inner quoted in the shell or SQL style named by style.
+func appendQuoted(dst, inner, style []byte) []byte {
+	switch strings.ToLower(string(bytes.TrimSpace(style))) {
+	case "double":
+		dst = append(dst, '"')
+		for _, c := range inner {
+			switch c {
+			case '"', '\\', '$', '`':
+				dst = append(dst, '\\')
+			}
+			dst = append(dst, c)
+		}
+		return append(dst, '"')
+	case "sql":
+		dst = append(dst, '\'')
+		for _, c := range inner {
+			if c == '\'' {
+				dst = append(dst, '\'')
+			}
+			dst = append(dst, c)
+		}
+		return append(dst, '\'')
+	}
+	dst = append(dst, '\'')
+	for _, c := range inner {
+		if c == '\'' {
+			dst = append(dst, `'\''`...)
+			continue
+		}
+		dst = append(dst, c)
+	}
+	return append(dst, '\'')
+}
+
+var (
+	markdownKinds = []string{"heading", "list", "emphasis", "link"}
+	markdownWords = []string{
+		"alpha", "bravo", "cache", "delta", "engine", "filter", "gamma", "header",
+		"index", "jitter", "kernel", "lambda", "module", "node", "offset", "packet",
+		"query", "random", "socket", "token", "update", "vector", "window", "yield",
+	}
+	linkHostPrefixes = []string{"www", "docs", "blog", "api"}
+)
+
+// appendMarkdown appends a Markdown fragment of the given kind.
+func appendMarkdown(r Source, dst []byte, length int, kind string) []byte {
+	if !slices.Contains(markdownKinds, kind) {
+		kind = pick(r, markdownKinds)
+	}
+	length = max(length, 1)
+	switch kind {
+	case "heading":
+		dst = append(dst, "######"[:intRange(r, 1, 6)]...)
+		dst = append(dst, ' ')
+		return appendMarkdownWords(r, dst, length)
+	case "list":
+		numbered, items := randBool(r), intRange(r, 2, 5)
+		for i := 1; i <= items; i++ {
+			if numbered {
+				dst = strconv.AppendInt(dst, int64(i), 10)
+				dst = append(dst, ". "...)
+			} else {
+				dst = append(dst, "- "...)
+			}
+			dst = appendMarkdownWords(r, dst, length)
+			dst = append(dst, '\n')
+		}
+		return dst
+	case "emphasis":
+		// A single word can only be bold.
+		bold, italic := intN(r, length), -1
+		if length > 1 {
+			italic = (bold + 1 + intN(r, length-1)) % length
+		}
+		for i := 0; i < length; i++ {
+			if i > 0 {
+				dst = append(dst, ' ')
+			}
+			word := pick(r, markdownWords)
+			switch i {
+			case bold:
+				dst = append(append(append(dst, "**"...), word...), "**"...)
+			case italic:
+				dst = append(append(append(dst, '*'), word...), '*')
+			default:
+				dst = append(dst, word...)
+			}
+		}
+		return dst
+	}
+	dst = append(dst, '[')
+	dst = appendMarkdownWords(r, dst, length)
+	dst = append(dst, "](https://"...)
+	dst = appendHostname(r, dst, linkHostPrefixes)
+	dst = append(dst, '/')
+	dst = append(dst, pick(r, markdownWords)...)
+	return append(dst, ')')
+}
+
+func appendMarkdownWords(r Source, dst []byte, n int) []byte {
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = append(dst, pick(r, markdownWords)...)
+	}
+	return dst
+}
+
+// onceTag splits the argument of ONCE and REMEMBER into a name and a tag.
+func onceTag(arg []byte) (name string, tag []byte) {
+	namePart, spec, _ := bytes.Cut(arg, []byte{sepTag})
+	tag = append(append(append([]byte(nil), startTag...), sepTag), spec...)
+	return string(namePart), append(tag, endTag)
+}
+
+// generateOnce expands arg once per name and call.
+func (e *FastEngine) generateOnce(arg []byte, state *callState) []byte {
+	name, tag := onceTag(arg)
+	return state.remember(name, func() []byte {
+		return e.expandArg(tag, state)
+	})
+}
+
+// generateRemember expands arg and adds the value to its pool.
+func (e *FastEngine) generateRemember(arg []byte, state *callState) []byte {
+	name, tag := onceTag(arg)
+	value := e.expandArg(tag, state)
+	e.rememberValue(name, value)
+	return value
+}
+
+// generateCRLFInject inserts inject sequences into base.
+func (e *FastEngine) generateCRLFInject(base []byte, state *callState) []byte {
+	r := e.src
+	positions := make([]int, intRange(r, 1, 3))
+	for i := range positions {
+		positions[i] = intN(r, len(base)+1)
+		for positions[i] < len(base) && !utf8.RuneStart(base[positions[i]]) {
+			positions[i]++
+		}
+	}
+	slices.Sort(positions)
+
+	out := make([]byte, 0, len(base)+32*len(positions))
+	last := 0
+	for _, pos := range positions {
+		out = append(out, base[last:pos]...)
+		out = append(out, e.expandArg([]byte(pick(r, e.injectSequences)), state)...)
+		last = pos
+	}
+	return append(out, base[last:]...)
+}
+
+// statusCodes groups the net/http status codes by class.
+var statusCodes = func() map[string][]int {
+	groups := make(map[string][]int)
+	for code := 100; code < 600; code++ {
+		if http.StatusText(code) != "" {
+			class := strconv.Itoa(code/100) + "xx"
+			groups[class] = append(groups[class], code)
+			groups[""] = append(groups[""], code)
+		}
+	}
+	return groups
+}()
+
+func statusCodesFor(class []byte) []int {
+	if codes, ok := statusCodes[strings.ToLower(string(class))]; ok {
+		return codes
+	}
+	return statusCodes[""]
+}
@@ -0,0 +1,2024 @@
+package fastrand_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SyNdicateFoundation/fastrand"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMimeKeyword(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString("{RAND;;MIME}")
+			if !strings.Contains(result, "/") {
+				t.Fatalf("Expected a MIME type containing '/', got %q", result)
+			}
+		}
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString("{RAND;;MIME;image}")
+			if !strings.HasPrefix(result, "image/") {
+				t.Fatalf("Expected an image MIME type, got %q", result)
+			}
+		}
+	})
+
+	t.Run("UnknownCategory", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;MIME;nosuchcategory}")
+		if !strings.Contains(result, "/") {
+			t.Errorf("Expected unknown category to fall back to all types, got %q", result)
+		}
+	})
+
+	t.Run("WithMimeTypes", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithMimeTypes([]string{"text/x-custom", "image/x-custom"}))
+		for i := 0; i < 100; i++ {
+			result := engine.RandomizerString("{RAND;;MIME;text}")
+			if result != "text/x-custom" {
+				t.Fatalf("Expected overridden text MIME type, got %q", result)
+			}
+			result = engine.RandomizerString("{RAND;;MIME}")
+			if result != "text/x-custom" && result != "image/x-custom" {
+				t.Fatalf("Expected an overridden MIME type, got %q", result)
+			}
+		}
+	})
+}
+
+func TestHashKeywords(t *testing.T) {
+	t.Run("DigestLength", func(t *testing.T) {
+		cases := map[string]int{
+			"{RAND;;SHA256}": 64,
+			"{RAND;;SHA1}":   40,
+			"{RAND;;MD5}":    32,
+		}
+		for template, expected := range cases {
+			result := fastrand.RandomizerString(template)
+			if len(result) != expected {
+				t.Errorf("%s: expected length %d, got %d (%q)", template, expected, len(result), result)
+			}
+			checkHexFormat(t, []byte(result))
+		}
+	})
+
+	t.Run("KnownDigest", func(t *testing.T) {
+		cases := map[string]string{
+			"{RAND;;SHA256;abc}": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+			"{RAND;;SHA1;abc}":   "a9993e364706816aba3e25717850c26c9cd0d89d",
+			"{RAND;;MD5;abc}":    "900150983cd24fb0d6963f7d28e17f72",
+		}
+		for template, expected := range cases {
+			if result := fastrand.RandomizerString(template); result != expected {
+				t.Errorf("%s: expected %s, got %s", template, expected, result)
+			}
+		}
+	})
+
+	t.Run("RandomInput", func(t *testing.T) {
+		if fastrand.RandomizerString("{RAND;;SHA256}") == fastrand.RandomizerString("{RAND;;SHA256}") {
+			t.Error("Expected hashes of random input to differ")
+		}
+	})
+
+	t.Run("NestedArgument", func(t *testing.T) {
+		result := fastrand.RandomizerString("hash={RAND;;SHA1;{RAND;8;HEX}}!")
+		if !strings.HasPrefix(result, "hash=") || !strings.HasSuffix(result, "!") {
+			t.Fatalf("Expected nested tag to be consumed as a whole, got %q", result)
+		}
+		digest := strings.TrimSuffix(strings.TrimPrefix(result, "hash="), "!")
+		if len(digest) != 40 {
+			t.Errorf("Expected SHA1 digest of length 40, got %q", digest)
+		}
+	})
+}
+
+func TestIPv4Formats(t *testing.T) {
+	t.Run("Int", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString("{RAND;;IPV4;int}")
+			value, err := strconv.ParseUint(result, 10, 32)
+			if err != nil {
+				t.Fatalf("Expected a 32-bit decimal integer, got %q: %v", result, err)
+			}
+			ip := make(net.IP, net.IPv4len)
+			binary.BigEndian.PutUint32(ip, uint32(value))
+			checkIPv4Format(t, []byte(ip.String()))
+		}
+	})
+
+	t.Run("Hex", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString("{RAND;;IPV4;hex}")
+			if len(result) != 10 || !strings.HasPrefix(result, "0x") {
+				t.Fatalf("Expected 0x-prefixed 8 digit hex, got %q", result)
+			}
+			value, err := strconv.ParseUint(result[2:], 16, 32)
+			if err != nil {
+				t.Fatalf("Expected valid hex, got %q: %v", result, err)
+			}
+			if result[2:] != strings.ToUpper(result[2:]) {
+				t.Errorf("Expected uppercase hex digits, got %q", result)
+			}
+			ip := make(net.IP, net.IPv4len)
+			binary.BigEndian.PutUint32(ip, uint32(value))
+			checkIPv4Format(t, []byte(ip.String()))
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		checkIPv4Format(t, []byte(fastrand.RandomizerString("{RAND;;IPV4}")))
+		checkIPv4Format(t, []byte(fastrand.RandomizerString("{RAND;;IPV4;unknown}")))
+	})
+}
+
+var semverRegex = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+func TestSemverKeyword(t *testing.T) {
+	t.Run("Plain", func(t *testing.T) {
+		for i := 0; i < 200; i++ {
+			result := fastrand.RandomizerString("{RAND;;SEMVER}")
+			if !semverRegex.MatchString(result) || strings.ContainsAny(result, "-+") {
+				t.Fatalf("Expected a plain semver, got %q", result)
+			}
+		}
+	})
+
+	t.Run("Prerelease", func(t *testing.T) {
+		sawPrerelease := false
+		for i := 0; i < 200; i++ {
+			result := fastrand.RandomizerString("{RAND;;SEMVER;pre}")
+			if !semverRegex.MatchString(result) {
+				t.Fatalf("Expected a valid semver, got %q", result)
+			}
+			if strings.Contains(result, "-") {
+				sawPrerelease = true
+			}
+		}
+		if !sawPrerelease {
+			t.Error("Expected at least one prerelease version")
+		}
+	})
+
+	t.Run("Build", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;SEMVER;pre+build}")
+		if !semverRegex.MatchString(result) || !strings.Contains(result, "+build.") {
+			t.Errorf("Expected semver with build metadata, got %q", result)
+		}
+	})
+}
+
+// semverBound is a version with whether the bound includes it.
+type semverBound struct {
+	version   [3]int
+	inclusive bool
+}
+
+var semverComparatorRegex = regexp.MustCompile(`^(>=|<=|>|<|\^|~)?(\d+)\.(\d+)\.(\d+)$`)
+
+// parseSemverConstraint parses the constraint forms SEMVERRANGE emits into
+// the interval they allow. A nil upper bound is unbounded.
+func parseSemverConstraint(constraint string) (lower semverBound, upper *semverBound, err error) {
+	parts := strings.Split(constraint, " ")
+	if len(parts) == 3 && parts[1] == "-" {
+		parts = []string{">=" + parts[0], "<=" + parts[2]}
+	}
+	if len(parts) > 2 {
+		return lower, nil, fmt.Errorf("too many comparators in %q", constraint)
+	}
+	lower.inclusive = true
+	for _, part := range parts {
+		m := semverComparatorRegex.FindStringSubmatch(part)
+		if m == nil {
+			return lower, nil, fmt.Errorf("invalid comparator %q", part)
+		}
+		var v [3]int
+		for i := range v {
+			v[i], _ = strconv.Atoi(m[2+i])
+		}
+		switch m[1] {
+		case ">", ">=":
+			lower = semverBound{v, m[1] == ">="}
+		case "<", "<=":
+			upper = &semverBound{v, m[1] == "<="}
+		case "^", "~":
+			lower = semverBound{v, true}
+			next := v
+			switch {
+			case m[1] == "~":
+				next = [3]int{v[0], v[1] + 1, 0}
+			case v[0] > 0:
+				next = [3]int{v[0] + 1, 0, 0}
+			case v[1] > 0:
+				next = [3]int{0, v[1] + 1, 0}
+			default:
+				next[2]++
+			}
+			upper = &semverBound{next, false}
+		default:
+			return lower, nil, fmt.Errorf("missing operator in %q", part)
+		}
+	}
+	return lower, upper, nil
+}
+
+func TestSemverRangeKeyword(t *testing.T) {
+	for _, form := range []string{"", "caret", "tilde", "range", "hyphen", "compare", "bogus"} {
+		t.Run("Form"+form, func(t *testing.T) {
+			for i := 0; i < 300; i++ {
+				constraint := fastrand.RandomizerString("{RAND;;SEMVERRANGE;" + form + "}")
+				lower, upper, err := parseSemverConstraint(constraint)
+				if err != nil {
+					t.Fatalf("Expected a valid constraint, got %q: %v", constraint, err)
+				}
+				if upper == nil {
+					continue
+				}
+				cmp := slices.Compare(lower.version[:], upper.version[:])
+				if cmp > 0 || (cmp == 0 && !(lower.inclusive && upper.inclusive)) {
+					t.Fatalf("Expected satisfiable bounds in %q", constraint)
+				}
+			}
+		})
+	}
+
+	prefixes := map[string]string{"caret": "^", "tilde": "~", "range": ">="}
+	for form, prefix := range prefixes {
+		if constraint := fastrand.RandomizerString("{RAND;;SEMVERRANGE;" + form + "}"); !strings.HasPrefix(constraint, prefix) {
+			t.Errorf("Expected the %s form to start with %q, got %q", form, prefix, constraint)
+		}
+	}
+	if constraint := fastrand.RandomizerString("{RAND;;SEMVERRANGE;hyphen}"); !strings.Contains(constraint, " - ") {
+		t.Errorf("Expected a hyphen range, got %q", constraint)
+	}
+}
+
+var safeFilenameRegex = regexp.MustCompile(`^[a-z0-9]+\.[A-Za-z0-9_-]+$`)
+
+func TestFilenameKeyword(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString("{RAND;6;FILENAME}")
+			if !safeFilenameRegex.MatchString(result) {
+				t.Fatalf("Expected a safe file name, got %q", result)
+			}
+			if base := result[:strings.IndexByte(result, '.')]; len(base) != 6 {
+				t.Fatalf("Expected a base name of length 6, got %q", result)
+			}
+		}
+	})
+
+	t.Run("Extensions", func(t *testing.T) {
+		seen := make(map[string]int)
+		for i := 0; i < 200; i++ {
+			result := fastrand.RandomizerString("{RAND;;FILENAME;pdf,docx}")
+			if !safeFilenameRegex.MatchString(result) {
+				t.Fatalf("Expected a safe file name, got %q", result)
+			}
+			seen[result[strings.IndexByte(result, '.')+1:]]++
+		}
+		if len(seen) != 2 || seen["pdf"] == 0 || seen["docx"] == 0 {
+			t.Errorf("Expected only pdf and docx extensions, got %v", seen)
+		}
+	})
+
+	t.Run("UnsafeExtension", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;FILENAME;a/b}")
+		if !safeFilenameRegex.MatchString(result) {
+			t.Errorf("Expected unsafe extensions to be ignored, got %q", result)
+		}
+	})
+}
+
+func TestPathKeyword(t *testing.T) {
+	testCases := []struct {
+		template  string
+		prefix    string
+		separator string
+		trailing  bool
+	}{
+		{template: "{RAND;3;PATH}", prefix: "/", separator: "/"},
+		{template: "{RAND;4;PATH;trailing}", prefix: "/", separator: "/", trailing: true},
+		{template: "{RAND;2;PATH;relative}", prefix: "", separator: "/"},
+		{template: "{RAND;5;PATH;windows}", prefix: `C:\`, separator: `\`},
+		{template: "{RAND;3;PATH;relative,windows,trailing}", prefix: "", separator: `\`, trailing: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			expectedSegments, _ := strconv.Atoi(strings.Split(tc.template, ";")[1])
+			for i := 0; i < 50; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				if !strings.HasPrefix(result, tc.prefix) {
+					t.Fatalf("Expected prefix %q, got %q", tc.prefix, result)
+				}
+				if strings.HasSuffix(result, tc.separator) != tc.trailing {
+					t.Fatalf("Unexpected trailing separator state in %q", result)
+				}
+				if strings.Contains(result, "..") {
+					t.Fatalf("Path %q contains a traversal sequence", result)
+				}
+				body := strings.TrimSuffix(strings.TrimPrefix(result, tc.prefix), tc.separator)
+				segments := strings.Split(body, tc.separator)
+				if len(segments) != expectedSegments {
+					t.Fatalf("Expected %d segments, got %d in %q", expectedSegments, len(segments), result)
+				}
+				for _, segment := range segments {
+					if segment == "" || strings.ContainsAny(segment, `/\`) {
+						t.Fatalf("Invalid segment %q in %q", segment, result)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPIDKeyword(t *testing.T) {
+	t.Run("Random", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			result := fastrand.RandomizerString("{RAND;;PID}")
+			pid, err := strconv.Atoi(result)
+			if err != nil {
+				t.Fatalf("Expected a numeric PID, got %q", result)
+			}
+			if pid < 1 || pid > 4194304 {
+				t.Fatalf("PID %d out of range", pid)
+			}
+		}
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		if result := fastrand.RandomizerString("{RAND;;PID;self}"); result != strconv.Itoa(os.Getpid()) {
+			t.Errorf("Expected current PID %d, got %q", os.Getpid(), result)
+		}
+	})
+}
+
+var hexdumpLineRegex = regexp.MustCompile(`^[0-9a-f]{8}  ([0-9a-f]{2} | {3}){8} ([0-9a-f]{2} | {3}){8} \|[\x20-\x7e]{1,16}\|$`)
+
+func TestHexdumpKeyword(t *testing.T) {
+	for _, byteCount := range []int{1, 16, 20, 32, 45} {
+		t.Run(strconv.Itoa(byteCount), func(t *testing.T) {
+			result := fastrand.RandomizerString("{RAND;" + strconv.Itoa(byteCount) + ";HEXDUMP}")
+			lines := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+			if expected := (byteCount + 15) / 16; len(lines) != expected {
+				t.Fatalf("Expected %d lines, got %d:\n%s", expected, len(lines), result)
+			}
+			for i, line := range lines {
+				if !hexdumpLineRegex.MatchString(line) {
+					t.Fatalf("Line %d has an unexpected format: %q", i, line)
+				}
+				if !strings.HasPrefix(line, fmt.Sprintf("%08x", i*16)) {
+					t.Errorf("Line %d has the wrong offset: %q", i, line)
+				}
+				if strings.Index(line, "|") != 60 {
+					t.Errorf("Line %d has a misaligned ASCII column: %q", i, line)
+				}
+			}
+			lastCount := byteCount - (len(lines)-1)*16
+			ascii := lines[len(lines)-1][61 : len(lines[len(lines)-1])-1]
+			if len(ascii) != lastCount {
+				t.Errorf("Expected %d ASCII characters on the last line, got %q", lastCount, ascii)
+			}
+		})
+	}
+}
+
+func TestRFC3339Keyword(t *testing.T) {
+	allowedOffsets := map[int]bool{}
+	for _, minutes := range []int{-480, -420, -300, -240, -180, 0, 60, 120, 180, 330, 480, 540, 600, 765} {
+		allowedOffsets[minutes*60] = true
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
+	engine := fastrand.NewEngine(fastrand.WithTimeRange(start, end))
+
+	sawZulu := false
+	for i := 0; i < 500; i++ {
+		result := engine.RandomizerString("{RAND;;RFC3339}")
+		parsed, err := time.Parse(time.RFC3339, result)
+		if err != nil {
+			t.Fatalf("Expected a valid RFC 3339 timestamp, got %q: %v", result, err)
+		}
+		_, offset := parsed.Zone()
+		if !allowedOffsets[offset] {
+			t.Fatalf("Unexpected zone offset %d in %q", offset, result)
+		}
+		if strings.HasSuffix(result, "Z") {
+			sawZulu = true
+		}
+		if parsed.Before(start) || parsed.After(end) {
+			t.Fatalf("Timestamp %q outside of the configured range", result)
+		}
+	}
+	if !sawZulu {
+		t.Error("Expected the UTC 'Z' form to be chosen at least once")
+	}
+}
+
+var bcp47Regex = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+func TestLocaleKeyword(t *testing.T) {
+	known := make(map[string]bool, len(fastrand.Locales))
+	for _, locale := range fastrand.Locales {
+		known[locale] = true
+	}
+
+	testCases := []struct {
+		template   string
+		wantRegion int // 1 requires a region, -1 forbids it, 0 allows either
+	}{
+		{"{RAND;;LOCALE}", 0},
+		{"{RAND;;LOCALE;lang}", -1},
+		{"{RAND;;LOCALE;region}", 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				if !bcp47Regex.MatchString(result) || !known[result] {
+					t.Fatalf("Expected a known BCP 47 tag, got %q", result)
+				}
+				hasRegion := strings.Contains(result, "-")
+				if tc.wantRegion == 1 && !hasRegion || tc.wantRegion == -1 && hasRegion {
+					t.Fatalf("Tag %q does not match the requested form", result)
+				}
+			}
+		})
+	}
+}
+
+func TestUniqueEnumKeyword(t *testing.T) {
+	t.Run("Permutation", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString("{RAND;;UNIQUEENUM;c;a,b,c} {RAND;;UNIQUEENUM;c;a,b,c} {RAND;;UNIQUEENUM;c;a,b,c}")
+			parts := strings.Fields(result)
+			if len(parts) != 3 {
+				t.Fatalf("Expected three values, got %q", result)
+			}
+			seen := map[string]bool{}
+			for _, p := range parts {
+				if p != "a" && p != "b" && p != "c" || seen[p] {
+					t.Fatalf("Expected a permutation of a,b,c, got %q", result)
+				}
+				seen[p] = true
+			}
+		}
+	})
+
+	t.Run("SetsAreIndependent", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;UNIQUEENUM;x;1}{RAND;;UNIQUEENUM;y;1}")
+		if result != "11" {
+			t.Errorf("Expected each set to draw its only value, got %q", result)
+		}
+	})
+
+	t.Run("ExhaustionStartsOver", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;UNIQUEENUM;s;a,b}{RAND;;UNIQUEENUM;s;a,b}{RAND;;UNIQUEENUM;s;a,b}{RAND;;UNIQUEENUM;s;a,b}")
+		if len(result) != 4 || result[0] == result[1] || result[2] == result[3] {
+			t.Errorf("Expected two full rounds of the set, got %q", result)
+		}
+	})
+
+	t.Run("ScopedToOneCall", func(t *testing.T) {
+		seen := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			seen[fastrand.RandomizerString("{RAND;;UNIQUEENUM;s;a,b,c}")] = true
+		}
+		if len(seen) != 3 {
+			t.Errorf("Expected every call to start from the full set, saw %v", seen)
+		}
+	})
+}
+
+var identRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func TestIdentKeyword(t *testing.T) {
+	for _, length := range []int{1, 2, 16, 99} {
+		template := fmt.Sprintf("{RAND;%d;IDENT}", length)
+		for i := 0; i < 200; i++ {
+			result := fastrand.RandomizerString(template)
+			if len(result) != length {
+				t.Fatalf("Expected length %d, got %d (%q)", length, len(result), result)
+			}
+			if !identRegex.MatchString(result) {
+				t.Fatalf("Expected a valid identifier, got %q", result)
+			}
+		}
+	}
+}
+
+func TestQueryKeyword(t *testing.T) {
+	for _, pairs := range []int{1, 3, 20} {
+		template := fmt.Sprintf("{RAND;%d;QUERY}", pairs)
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString(template)
+			values, err := url.ParseQuery(result)
+			if err != nil {
+				t.Fatalf("Expected a valid query string, got %q: %v", result, err)
+			}
+			if len(values) != pairs {
+				t.Fatalf("Expected %d keys, got %d in %q", pairs, len(values), result)
+			}
+			for key, vals := range values {
+				if len(vals) != 1 || vals[0] == "" {
+					t.Fatalf("Expected a single non-empty value for %q in %q", key, result)
+				}
+			}
+			if strings.ContainsAny(result, " #\"<>{}") {
+				t.Fatalf("Expected special characters to be percent-encoded, got %q", result)
+			}
+		}
+	}
+
+	// More pairs than there are keys of up to three letters.
+	// url.ParseQuery stops at 10000 pairs, so the keys are collected here.
+	engine := fastrand.NewEngine(fastrand.WithMaxLength(100000))
+	keys := make(map[string]bool)
+	for _, pair := range strings.Split(engine.RandomizerString("{RAND;20000;QUERY}"), "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		keys[key] = true
+	}
+	if len(keys) != 20000 {
+		t.Errorf("Expected 20000 distinct keys, got %d", len(keys))
+	}
+}
+
+func TestJSONValueKeyword(t *testing.T) {
+	testCases := []struct {
+		typ   string
+		check func(v any) bool
+	}{
+		{"string", func(v any) bool { _, ok := v.(string); return ok }},
+		{"number", func(v any) bool { _, ok := v.(float64); return ok }},
+		{"bool", func(v any) bool { _, ok := v.(bool); return ok }},
+		{"null", func(v any) bool { return v == nil }},
+		{"array", func(v any) bool { a, ok := v.([]interface{}); return ok && len(a) <= 5 }},
+		{"nonsense", func(v any) bool { _, ok := v.(string); return ok }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.typ, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				result := fastrand.RandomizerString("{RAND;;JSONVAL;" + tc.typ + "}")
+				var v any
+				if err := json.Unmarshal([]byte(result), &v); err != nil {
+					t.Fatalf("Expected valid JSON, got %q: %v", result, err)
+				}
+				if !tc.check(v) {
+					t.Fatalf("Unexpected value %q for type %s", result, tc.typ)
+				}
+			}
+		})
+	}
+
+	var sawInt, sawFloat bool
+	for i := 0; i < 200 && !(sawInt && sawFloat); i++ {
+		if strings.Contains(fastrand.RandomizerString("{RAND;;JSONVAL;number}"), ".") {
+			sawFloat = true
+		} else {
+			sawInt = true
+		}
+	}
+	if !sawInt || !sawFloat {
+		t.Error("Expected numbers to include both integers and floats")
+	}
+}
+
+func TestRuleKeyword(t *testing.T) {
+	testCases := []struct {
+		template string
+		want     string
+	}{
+		{"{RAND;40;RULE}", strings.Repeat("-", 40)},
+		{"{RAND;10;RULE;=}", strings.Repeat("=", 10)},
+		{"{RAND;5;RULE;─}", strings.Repeat("─", 5)},
+		{"{RAND;3;RULE;*#}", "***"},
+		{"{RAND;1;RULE;~}", "~"},
+	}
+	for _, tc := range testCases {
+		if result := fastrand.RandomizerString(tc.template); result != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.template, tc.want, result)
+		}
+	}
+}
+
+func TestMACKeyword(t *testing.T) {
+	t.Run("OUI", func(t *testing.T) {
+		seen := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString("{RAND;;MAC;00:1A:2B}")
+			mac, err := net.ParseMAC(result)
+			if err != nil || len(mac) != 6 {
+				t.Fatalf("Expected a valid MAC, got %q", result)
+			}
+			if mac[0] != 0x00 || mac[1] != 0x1A || mac[2] != 0x2B {
+				t.Fatalf("Expected the OUI 00:1A:2B to be preserved, got %q", result)
+			}
+			seen[result] = true
+		}
+		if len(seen) < 90 {
+			t.Errorf("Expected the NIC-specific bytes to vary, got %d distinct addresses", len(seen))
+		}
+	})
+
+	t.Run("InvalidOUIFallsBack", func(t *testing.T) {
+		for _, oui := range []string{"", "zz:1a:2b", "001A2B", "00:1A", "00:1A-2B"} {
+			for i := 0; i < 50; i++ {
+				result := fastrand.RandomizerString("{RAND;;MAC;" + oui + "}")
+				mac, err := net.ParseMAC(result)
+				if err != nil || len(mac) != 6 {
+					t.Fatalf("OUI %q: expected a valid MAC, got %q", oui, result)
+				}
+				if mac[0]&1 != 0 {
+					t.Fatalf("OUI %q: expected a unicast MAC, got %q", oui, result)
+				}
+			}
+		}
+	})
+}
+
+func TestFlagKeyword(t *testing.T) {
+	testCases := []struct {
+		format  string
+		yes, no string
+	}{
+		{"truefalse", "true", "false"},
+		{"onoff", "on", "off"},
+		{"yesno", "yes", "no"},
+		{"10", "1", "0"},
+		{"", "true", "false"},
+		{"bogus", "true", "false"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			seen := map[string]bool{}
+			for i := 0; i < 200; i++ {
+				result := fastrand.RandomizerString("{RAND;;FLAG;" + tc.format + "}")
+				if result != tc.yes && result != tc.no {
+					t.Fatalf("Expected %q or %q, got %q", tc.yes, tc.no, result)
+				}
+				seen[result] = true
+			}
+			if len(seen) != 2 {
+				t.Errorf("Expected both tokens, saw %v", seen)
+			}
+		})
+	}
+
+	t.Run("Bias", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			if result := fastrand.RandomizerString("{RAND;;FLAG;onoff;100}"); result != "on" {
+				t.Fatalf("Expected a 100%% bias to always be on, got %q", result)
+			}
+			if result := fastrand.RandomizerString("{RAND;;FLAG;onoff;0}"); result != "off" {
+				t.Fatalf("Expected a 0%% bias to always be off, got %q", result)
+			}
+		}
+	})
+}
+
+func TestCRLFInjectKeyword(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		injected := regexp.MustCompile(`\r\nX-Injected: [A-Za-z]{8}|\nX-Injected: [A-Za-z]{8}|%0d%0aX-Injected: [A-Za-z]{8}|\r\n\r\n|\r|;`)
+		for i := 0; i < 200; i++ {
+			result := fastrand.RandomizerString("{RAND;;CRLFINJECT;session=abc123}")
+			if result == "session=abc123" {
+				t.Fatalf("Expected at least one injected sequence in %q", result)
+			}
+			if stripped := injected.ReplaceAllString(result, ""); stripped != "session=abc123" {
+				t.Fatalf("Expected the base to survive around the injections, got %q from %q", stripped, result)
+			}
+		}
+	})
+
+	t.Run("CustomSequencesAndNestedBase", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithInjectSequences("<{RAND;2;DIGIT}>"))
+		for i := 0; i < 200; i++ {
+			result := engine.RandomizerString("{RAND;;CRLFINJECT;id={RAND;6;ABU}-é}")
+			n := strings.Count(result, "<")
+			if n < 1 || n > 3 {
+				t.Fatalf("Expected one to three injections, got %d in %q", n, result)
+			}
+			stripped := regexp.MustCompile(`<[0-9]{2}>`).ReplaceAllString(result, "")
+			if !regexp.MustCompile(`^id=[A-Z]{6}-é$`).MatchString(stripped) {
+				t.Fatalf("Expected the expanded base to be preserved, got %q from %q", stripped, result)
+			}
+		}
+	})
+}
+
+func TestUUIDVersions(t *testing.T) {
+	check := func(t *testing.T, template string, version byte) string {
+		t.Helper()
+		result := fastrand.RandomizerString(template)
+		if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`).MatchString(result) {
+			t.Fatalf("Expected a UUID, got %q", result)
+		}
+		if result[14] != version {
+			t.Fatalf("Expected version %c, got %q", version, result)
+		}
+		if !strings.ContainsRune("89ab", rune(result[19])) {
+			t.Fatalf("Expected RFC 4122 variant bits, got %q", result)
+		}
+		return result
+	}
+
+	for i := 0; i < 50; i++ {
+		check(t, "{RAND;;UUID}", '4')
+		check(t, "{RAND;;UUID;v4}", '4')
+		check(t, "{RAND;;UUID;v9}", '4')
+		check(t, "{RAND;;UUID;V7}", '7')
+	}
+
+	var previous string
+	for i := 0; i < 5; i++ {
+		current := check(t, "{RAND;;UUID;v7}", '7')
+		if current <= previous {
+			t.Fatalf("Expected v7 UUIDs to sort by creation time, got %q after %q", current, previous)
+		}
+		previous = current
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	prefix := fastrand.RandomizerString("{RAND;;UUID;v7}")
+	ms, err := strconv.ParseInt(strings.ReplaceAll(prefix[:13], "-", ""), 16, 64)
+	if err != nil || time.Since(time.UnixMilli(ms)).Abs() > time.Minute {
+		t.Errorf("Expected the timestamp prefix to be the current time, got %q", prefix)
+	}
+
+	t.Run("V1", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			uuid := check(t, "{RAND;;UUID;V1}", '1')
+			node, err := hex.DecodeString(uuid[24:])
+			if err != nil || node[0]&0x01 == 0 {
+				t.Fatalf("Expected a node with the multicast bit set, got %q", uuid)
+			}
+		}
+
+		uuid := strings.ReplaceAll(fastrand.RandomizerString("{RAND;;UUID;v1}"), "-", "")
+		ts, err := strconv.ParseUint(uuid[13:16]+uuid[8:12]+uuid[0:8], 16, 64)
+		if err != nil {
+			t.Fatalf("Expected a hex timestamp, got %q", uuid)
+		}
+		const gregorianOffset = 122192928000000000
+		created := time.Unix(0, int64(ts-gregorianOffset)*100)
+		if time.Since(created).Abs() > time.Minute {
+			t.Errorf("Expected the timestamp to be the current time, got %v", created)
+		}
+	})
+}
+
+func TestStatusKeyword(t *testing.T) {
+	testCases := []struct {
+		template string
+		class    int
+	}{
+		{"{RAND;;STATUS}", 0},
+		{"{RAND;;STATUS;2xx}", 2},
+		{"{RAND;;STATUS;4XX}", 4},
+		{"{RAND;;STATUS;5xx}", 5},
+		{"{RAND;;STATUS;9xx}", 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				code, err := strconv.Atoi(result)
+				if err != nil || len(result) != 3 || http.StatusText(code) == "" {
+					t.Fatalf("Expected a known 3-digit status code, got %q", result)
+				}
+				if tc.class != 0 && code/100 != tc.class {
+					t.Fatalf("Expected a %dxx code, got %d", tc.class, code)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiKeyword(t *testing.T) {
+	known := map[string]bool{}
+	for _, p := range fastrand.SQLiPayloads {
+		known[p] = true
+	}
+	for i := 0; i < 200; i++ {
+		if result := fastrand.RandomizerString("{RAND;;SQLI}"); !known[result] {
+			t.Fatalf("Expected a payload from the embedded list, got %q", result)
+		}
+	}
+
+	engine := fastrand.NewEngine(fastrand.WithSQLiPayloads([]string{"{RAND;8;DIGIT}' OR 1=1--"}))
+	if result := engine.RandomizerString("q={RAND;;SQLI}"); result != "q={RAND;8;DIGIT}' OR 1=1--" {
+		t.Errorf("Expected the override to be emitted verbatim, got %q", result)
+	}
+}
+
+func TestXSSKeyword(t *testing.T) {
+	known := map[string]bool{}
+	for _, p := range fastrand.XSSPayloads {
+		known[p] = true
+	}
+	for i := 0; i < 200; i++ {
+		if result := fastrand.RandomizerString("{RAND;;XSS}"); !known[result] {
+			t.Fatalf("Expected a payload from the embedded list, got %q", result)
+		}
+	}
+
+	override := fastrand.WithXssPayloads([]string{`<b onmouseover="x">`})
+	if result := fastrand.NewEngine(override).RandomizerString("{RAND;;XSS}"); result != `<b onmouseover="x">` {
+		t.Errorf("Expected the override, got %q", result)
+	}
+
+	htmlEngine := fastrand.NewEngine(override, fastrand.WithOutputEncoding(fastrand.RandomizerEncodingHTML))
+	if result := htmlEngine.RandomizerString("<p>{RAND;;XSS}"); result != `&lt;p&gt;<b onmouseover="x">` {
+		t.Errorf("Expected the payload to stay raw like other generated values, got %q", result)
+	}
+	if result := htmlEngine.RandomizerString("{RAND;;XSS;encoded}"); result != `&lt;b onmouseover=&#34;x&#34;&gt;` {
+		t.Errorf("Expected the encoded modifier to apply HTML output encoding, got %q", result)
+	}
+	if result := fastrand.NewEngine(override).RandomizerString("{RAND;;XSS;encoded}"); result != `<b onmouseover="x">` {
+		t.Errorf("Expected no escaping without an output encoding, got %q", result)
+	}
+	want := fastrand.RandomizerString(`{RAND;;SHA256;<b onmouseover="x">}`)
+	if result := htmlEngine.RandomizerString("{RAND;;SHA256;{RAND;;XSS;encoded}}"); result != want {
+		t.Errorf("Expected nested arguments, which are not output encoded, to hash the raw payload, got %q", result)
+	}
+}
+
+func TestOIDKeyword(t *testing.T) {
+	oidRegex := regexp.MustCompile(`^[0-9]+(\.[0-9]+)+$`)
+	testCases := []struct {
+		template string
+		arcs     int
+	}{
+		{"{RAND;6;OID}", 6},
+		{"{RAND;2;OID}", 2},
+		{"{RAND;1;OID}", 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				if !oidRegex.MatchString(result) {
+					t.Fatalf("Expected a dotted-decimal OID, got %q", result)
+				}
+				arcs := strings.Split(result, ".")
+				if len(arcs) != tc.arcs {
+					t.Fatalf("Expected %d arcs, got %q", tc.arcs, result)
+				}
+				first, _ := strconv.Atoi(arcs[0])
+				second, _ := strconv.Atoi(arcs[1])
+				if first > 2 {
+					t.Fatalf("Expected the first arc to be 0-2, got %q", result)
+				}
+				if first < 2 && second > 39 {
+					t.Fatalf("Expected the second arc to be 0-39 under arc %d, got %q", first, result)
+				}
+			}
+		})
+	}
+}
+
+func TestBaseNKeyword(t *testing.T) {
+	testCases := []struct {
+		template string
+		digits   string
+		length   int
+	}{
+		{"{RAND;8;BASEN;36}", "0123456789abcdefghijklmnopqrstuvwxyz", 8},
+		{"{RAND;16;BASEN;2}", "01", 16},
+		{"{RAND;5;BASEN;16}", "0123456789abcdef", 5},
+		{"{RAND;6;BASEN;37}", "0123456789", 6},
+		{"{RAND;6;BASEN}", "0123456789", 6},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				if len(result) != tc.length || strings.Trim(result, tc.digits) != "" {
+					t.Fatalf("Expected %d digits from %q, got %q", tc.length, tc.digits, result)
+				}
+			}
+		})
+	}
+
+	t.Run("Max", func(t *testing.T) {
+		for i := 0; i < 200; i++ {
+			result := fastrand.RandomizerString("{RAND;;BASEN;16;ff}")
+			v, err := strconv.ParseInt(result, 16, 64)
+			if err != nil || v > 0xff || result != strings.ToLower(result) {
+				t.Fatalf("Expected a lowercase hex value up to ff, got %q", result)
+			}
+		}
+	})
+}
+
+func TestBracketsKeyword(t *testing.T) {
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{', '>': '<'}
+	testCases := []struct {
+		template string
+		length   int
+		allowed  string
+	}{
+		{"{RAND;6;BRACKETS}", 6, "()[]{}"},
+		{"{RAND;7;BRACKETS}", 8, "()[]{}"},
+		{"{RAND;1;BRACKETS}", 2, "()[]{}"},
+		{"{RAND;20;BRACKETS;round,angle}", 20, "()<>"},
+		{"{RAND;20;BRACKETS;curly}", 20, "{}"},
+		{"{RAND;20;BRACKETS;[}", 20, "[]"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				if len(result) != tc.length {
+					t.Fatalf("Expected %d brackets, got %q", tc.length, result)
+				}
+				var stack []rune
+				for _, c := range result {
+					if !strings.ContainsRune(tc.allowed, c) {
+						t.Fatalf("Unexpected bracket %q in %q", c, result)
+					}
+					if open, isClose := pairs[c]; isClose {
+						if len(stack) == 0 || stack[len(stack)-1] != open {
+							t.Fatalf("Unbalanced output %q", result)
+						}
+						stack = stack[:len(stack)-1]
+					} else {
+						stack = append(stack, c)
+					}
+				}
+				if len(stack) != 0 {
+					t.Fatalf("Unclosed brackets in %q", result)
+				}
+			}
+		})
+	}
+}
+
+func TestISBNKeyword(t *testing.T) {
+	t.Run("ISBN13", func(t *testing.T) {
+		for i := 0; i < 500; i++ {
+			result := fastrand.RandomizerString("{RAND;;ISBN}")
+			if !regexp.MustCompile(`^97[89][0-9]{10}$`).MatchString(result) {
+				t.Fatalf("Expected a 13-digit ISBN, got %q", result)
+			}
+			sum := 0
+			for j, c := range result {
+				weight := 1
+				if j%2 == 1 {
+					weight = 3
+				}
+				sum += weight * int(c-'0')
+			}
+			if sum%10 != 0 {
+				t.Fatalf("Invalid ISBN-13 check digit in %q", result)
+			}
+		}
+	})
+
+	t.Run("ISBN10", func(t *testing.T) {
+		for i := 0; i < 500; i++ {
+			result := fastrand.RandomizerString("{RAND;;ISBN;10}")
+			if !regexp.MustCompile(`^[0-9]{9}[0-9X]$`).MatchString(result) {
+				t.Fatalf("Expected a 10-character ISBN, got %q", result)
+			}
+			sum := 0
+			for j, c := range result {
+				digit := int(c - '0')
+				if c == 'X' {
+					digit = 10
+				}
+				sum += (10 - j) * digit
+			}
+			if sum%11 != 0 {
+				t.Fatalf("Invalid ISBN-10 check digit in %q", result)
+			}
+		}
+	})
+}
+
+func TestShortIDKeyword(t *testing.T) {
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	for i := 0; i < 200; i++ {
+		id := fastrand.RandomizerString("{RAND;8;SHORTID}")
+		if len(id) != 9 || strings.Trim(id, alphabet) != "" {
+			t.Fatalf("Expected 8 base62 characters plus a check character, got %q", id)
+		}
+		if !fastrand.VerifyShortID(id) {
+			t.Fatalf("Expected %q to verify", id)
+		}
+		for pos := 0; pos < len(id); pos++ {
+			for _, c := range []byte(alphabet) {
+				if c == id[pos] {
+					continue
+				}
+				corrupted := id[:pos] + string(c) + id[pos+1:]
+				if fastrand.VerifyShortID(corrupted) {
+					t.Fatalf("Expected %q (corrupted from %q) to be rejected", corrupted, id)
+				}
+			}
+		}
+	}
+
+	for _, id := range []string{"", "a", "abc-def", "héllo"} {
+		if fastrand.VerifyShortID(id) {
+			t.Errorf("Expected %q to be rejected", id)
+		}
+	}
+}
+
+func TestHeadersKeyword(t *testing.T) {
+	lineRegex := regexp.MustCompile(`^[A-Za-z][A-Za-z-]*: [A-Za-z0-9\-_.,;=/+]{1,32}$`)
+	for i := 0; i < 100; i++ {
+		result := fastrand.RandomizerString("{RAND;5;HEADERS}")
+		if !strings.HasSuffix(result, "\r\n") {
+			t.Fatalf("Expected the block to end in CRLF, got %q", result)
+		}
+		lines := strings.Split(strings.TrimSuffix(result, "\r\n"), "\r\n")
+		if len(lines) != 5 {
+			t.Fatalf("Expected 5 header lines, got %d in %q", len(lines), result)
+		}
+		names := make(map[string]bool)
+		for _, line := range lines {
+			if !lineRegex.MatchString(line) {
+				t.Fatalf("Expected a Name: value line, got %q", line)
+			}
+			name, _, _ := strings.Cut(line, ":")
+			if names[name] {
+				t.Fatalf("Duplicate header %q in %q", name, result)
+			}
+			names[name] = true
+		}
+	}
+
+	t.Run("CappedAtList", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;99;HEADERS}")
+		if n := strings.Count(result, "\r\n"); n != len(fastrand.HeaderNames) {
+			t.Errorf("Expected %d headers, got %d", len(fastrand.HeaderNames), n)
+		}
+	})
+
+	t.Run("Inject", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithInjectSequences("\r\nX-Injected: 1"))
+		result := engine.RandomizerString("{RAND;3;HEADERS;inject}")
+		if !strings.Contains(result, "\r\nX-Injected: 1") || strings.Count(result, "\r\n") < 4 {
+			t.Errorf("Expected an injected header, got %q", result)
+		}
+	})
+}
+
+func TestOnceKeyword(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		result := fastrand.RandomizerString("{RAND;;ONCE;token;8;HEX}|{RAND;;ONCE;token;8;HEX}|{RAND;;ONCE;token;8;HEX}|{RAND;;ONCE;other;8;HEX}")
+		parts := strings.Split(result, "|")
+		if len(parts) != 4 || len(parts[0]) != 16 {
+			t.Fatalf("Expected four 16-character hex values, got %q", result)
+		}
+		if parts[0] != parts[1] || parts[1] != parts[2] {
+			t.Fatalf("Expected the same name to repeat its value, got %q", result)
+		}
+		if parts[3] == parts[0] {
+			t.Fatalf("Expected a different name to get its own value, got %q", result)
+		}
+	}
+
+	t.Run("ScopedPerCall", func(t *testing.T) {
+		first := fastrand.RandomizerString("{RAND;;ONCE;token;16;HEX}")
+		if second := fastrand.RandomizerString("{RAND;;ONCE;token;16;HEX}"); first == second {
+			t.Errorf("Expected separate calls to generate separate values, got %q twice", first)
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;SHA1;{RAND;;ONCE;k;8;ABL}}={RAND;;SHA1;{RAND;;ONCE;k}}")
+		if left, right, _ := strings.Cut(result, "="); left != right {
+			t.Errorf("Expected nested ONCE tags to share their value, got %q", result)
+		}
+	})
+}
+
+func TestGeoKeyword(t *testing.T) {
+	testCases := []struct {
+		template                       string
+		minLat, minLon, maxLat, maxLon float64
+	}{
+		{"{RAND;;GEO;US}", 24.40, -124.85, 49.38, -66.89},
+		{"{RAND;;GEO;united states}", 24.40, -124.85, 49.38, -66.89},
+		{"{RAND;;GEO;jp}", 24.25, 122.93, 45.52, 145.82},
+		{"{RAND;;GEO;atlantis}", -90, -180, 90, 180},
+		{"{RAND;;GEO}", -90, -180, 90, 180},
+	}
+	geoRegex := regexp.MustCompile(`^-?[0-9]+\.[0-9]{6},-?[0-9]+\.[0-9]{6}$`)
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			outsideUS := false
+			for i := 0; i < 300; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				if !geoRegex.MatchString(result) {
+					t.Fatalf("Expected lat,lon with six decimals, got %q", result)
+				}
+				latPart, lonPart, _ := strings.Cut(result, ",")
+				lat, _ := strconv.ParseFloat(latPart, 64)
+				lon, _ := strconv.ParseFloat(lonPart, 64)
+				if lat < tc.minLat || lat > tc.maxLat || lon < tc.minLon || lon > tc.maxLon {
+					t.Fatalf("Coordinates %q are outside the expected box", result)
+				}
+				if lat < 24.40 || lat > 49.38 || lon < -124.85 || lon > -66.89 {
+					outsideUS = true
+				}
+			}
+			if tc.minLat == -90 && !outsideUS {
+				t.Error("Expected the global fallback to leave the US box")
+			}
+		})
+	}
+}
+
+func TestHashPickKeyword(t *testing.T) {
+	template := "{RAND;;HASHPICK;userid123;red,green,blue}"
+	first := fastrand.RandomizerString(template)
+	if first != "red" && first != "green" && first != "blue" {
+		t.Fatalf("Expected one of the choices, got %q", first)
+	}
+	for i := 0; i < 50; i++ {
+		if result := fastrand.RandomizerString(template); result != first {
+			t.Fatalf("Expected the same key to always pick %q, got %q", first, result)
+		}
+	}
+
+	t.Run("Distribution", func(t *testing.T) {
+		counts := make(map[string]int)
+		for i := 0; i < 3000; i++ {
+			counts[fastrand.RandomizerString(fmt.Sprintf("{RAND;;HASHPICK;user%d;red,green,blue}", i))]++
+		}
+		for _, color := range []string{"red", "green", "blue"} {
+			if counts[color] < 800 || counts[color] > 1200 {
+				t.Errorf("Expected about 1000 picks of %s, got %v", color, counts)
+			}
+		}
+	})
+
+	t.Run("NestedKey", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;HASHPICK;{RAND;;ONCE;k;8;HEX};a,b}{RAND;;HASHPICK;{RAND;;ONCE;k};a,b}")
+		if result != "aa" && result != "bb" {
+			t.Errorf("Expected equal keys to pick equal choices, got %q", result)
+		}
+	})
+
+	t.Run("NoChoices", func(t *testing.T) {
+		if result := fastrand.RandomizerString("[{RAND;;HASHPICK;key}]"); result != "[]" {
+			t.Errorf("Expected nothing without choices, got %q", result)
+		}
+	})
+}
+
+func TestCronKeyword(t *testing.T) {
+	fieldBounds := [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	checkValue := func(t *testing.T, s string, lo, hi int) int {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < lo || v > hi {
+			t.Fatalf("Value %q is outside [%d, %d]", s, lo, hi)
+		}
+		return v
+	}
+	checkField := func(t *testing.T, field string, lo, hi int) {
+		base, step, hasStep := strings.Cut(field, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n < 1 {
+				t.Fatalf("Invalid step in %q", field)
+			}
+		}
+		if base == "*" {
+			return
+		}
+		if start, end, isRange := strings.Cut(base, "-"); isRange {
+			if checkValue(t, start, lo, hi) >= checkValue(t, end, lo, hi) {
+				t.Fatalf("Invalid range %q", field)
+			}
+			return
+		}
+		if hasStep {
+			t.Fatalf("Step over a single value in %q", field)
+		}
+		checkValue(t, base, lo, hi)
+	}
+
+	testCases := []struct {
+		template string
+		bounds   [][2]int
+	}{
+		{"{RAND;;CRON}", fieldBounds},
+		{"{RAND;;CRON;seconds}", append([][2]int{{0, 59}}, fieldBounds...)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 300; i++ {
+				result := fastrand.RandomizerString(tc.template)
+				fields := strings.Split(result, " ")
+				if len(fields) != len(tc.bounds) {
+					t.Fatalf("Expected %d fields, got %q", len(tc.bounds), result)
+				}
+				for j, field := range fields {
+					checkField(t, field, tc.bounds[j][0], tc.bounds[j][1])
+				}
+			}
+		})
+	}
+}
+
+func TestProtoWireKeyword(t *testing.T) {
+	for i := 0; i < 300; i++ {
+		data := fastrand.Randomizer([]byte("{RAND;5;PROTOWIRE}"))
+		fields := 0
+		for len(data) > 0 {
+			tag, n := binary.Uvarint(data)
+			if n <= 0 {
+				t.Fatalf("Invalid tag varint in %x", data)
+			}
+			data = data[n:]
+			field, wireType := tag>>3, tag&7
+			if field < 1 || field > 1<<29-1 || field >= 19000 && field <= 19999 {
+				t.Fatalf("Invalid field number %d", field)
+			}
+			switch wireType {
+			case 0:
+				if _, n = binary.Uvarint(data); n <= 0 {
+					t.Fatalf("Invalid varint payload")
+				}
+				data = data[n:]
+			case 1:
+				if len(data) < 8 {
+					t.Fatalf("Truncated fixed64 payload")
+				}
+				data = data[8:]
+			case 2:
+				length, n := binary.Uvarint(data)
+				if n <= 0 || uint64(len(data)-n) < length {
+					t.Fatalf("Length-delimited field declares %d bytes, %d available", length, len(data)-n)
+				}
+				data = data[n+int(length):]
+			case 5:
+				if len(data) < 4 {
+					t.Fatalf("Truncated fixed32 payload")
+				}
+				data = data[4:]
+			default:
+				t.Fatalf("Unexpected wire type %d", wireType)
+			}
+			fields++
+		}
+		if fields != 5 {
+			t.Fatalf("Expected 5 fields, got %d", fields)
+		}
+	}
+}
+
+func TestMethodKeyword(t *testing.T) {
+	valid := map[string]bool{
+		"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true,
+		"HEAD": true, "OPTIONS": true, "CONNECT": true, "TRACE": true,
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		method := fastrand.RandomizerString("{RAND;;METHOD}")
+		if !valid[method] {
+			t.Fatalf("Unexpected method %q", method)
+		}
+		counts[method]++
+	}
+	if counts["GET"] < counts["POST"] || counts["POST"] < counts["PATCH"] {
+		t.Errorf("Expected GET to be most common, then POST, got %v", counts)
+	}
+
+	testCases := []struct {
+		template string
+		allowed  []string
+	}{
+		{"{RAND;;METHOD;safe}", []string{"GET", "HEAD", "OPTIONS"}},
+		{"{RAND;;METHOD;UNSAFE}", []string{"POST", "PUT", "DELETE", "PATCH"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 300; i++ {
+				if method := fastrand.RandomizerString(tc.template); !slices.Contains(tc.allowed, method) {
+					t.Fatalf("Expected one of %v, got %q", tc.allowed, method)
+				}
+			}
+		})
+	}
+
+	t.Run("UnknownClass", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i := 0; i < 500; i++ {
+			seen[fastrand.RandomizerString("{RAND;;METHOD;bogus}")] = true
+		}
+		if !seen["POST"] || !seen["GET"] {
+			t.Errorf("Expected all methods for an unknown class, got %v", seen)
+		}
+	})
+
+	t.Run("CustomWeights", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithMethodWeights(map[string]float64{"get": 1, "post": 0, "PURGE": 1}))
+		for i := 0; i < 200; i++ {
+			if method := engine.RandomizerString("{RAND;;METHOD}"); method != "GET" && method != "PURGE" {
+				t.Fatalf("Expected GET or PURGE, got %q", method)
+			}
+			if method := engine.RandomizerString("{RAND;;METHOD;unsafe}"); method != "GET" && method != "PURGE" {
+				t.Fatalf("Expected a class without weights to fall back, got %q", method)
+			}
+		}
+		if fastrand.NewEngine(fastrand.WithMethodWeights(map[string]float64{"GET": 0})).Err() == nil {
+			t.Error("Expected weights without a positive value to be rejected")
+		}
+	})
+}
+
+func TestColorKeyword(t *testing.T) {
+	colorRegex := regexp.MustCompile(`^#[0-9a-f]{6}$`)
+	material := []string{
+		"#f44336", "#e91e63", "#9c27b0", "#673ab7", "#3f51b5", "#2196f3", "#03a9f4", "#00bcd4", "#009688", "#4caf50",
+		"#8bc34a", "#cddc39", "#ffeb3b", "#ffc107", "#ff9800", "#ff5722", "#795548", "#9e9e9e", "#607d8b",
+	}
+
+	t.Run("Palette", func(t *testing.T) {
+		for i := 0; i < 200; i++ {
+			if color := fastrand.RandomizerString("{RAND;;COLOR;Material}"); !slices.Contains(material, color) {
+				t.Fatalf("Expected a material color, got %q", color)
+			}
+		}
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		for _, template := range []string{"{RAND;;COLOR}", "{RAND;;COLOR;nope}"} {
+			seen := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				color := fastrand.RandomizerString(template)
+				if !colorRegex.MatchString(color) {
+					t.Fatalf("Expected a hex color from %s, got %q", template, color)
+				}
+				seen[color] = true
+			}
+			if len(seen) < 90 {
+				t.Errorf("Expected fully random colors from %s, got %d distinct", template, len(seen))
+			}
+		}
+	})
+
+	t.Run("CustomPalette", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithPalette("Brand", []string{"#ABC", "112233"}))
+		if err := engine.Err(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for i := 0; i < 100; i++ {
+			if color := engine.RandomizerString("{RAND;;COLOR;brand}"); color != "#aabbcc" && color != "#112233" {
+				t.Fatalf("Expected a brand color, got %q", color)
+			}
+		}
+		if color := fastrand.RandomizerString("{RAND;;COLOR;brand}"); !colorRegex.MatchString(color) {
+			t.Errorf("Expected other engines to treat brand as unknown, got %q", color)
+		}
+	})
+
+	t.Run("InvalidPalette", func(t *testing.T) {
+		for _, colors := range [][]string{nil, {"#12345"}, {"#ff0000", "red"}} {
+			engine := fastrand.NewEngine(fastrand.WithPalette("bad", colors))
+			if engine.Err() == nil {
+				t.Errorf("Expected palette %q to be rejected", colors)
+			}
+			if color := engine.RandomizerString("{RAND;;COLOR;bad}"); !colorRegex.MatchString(color) {
+				t.Errorf("Expected a rejected palette to fall back to random colors, got %q", color)
+			}
+		}
+	})
+}
+
+func TestMagicKeyword(t *testing.T) {
+	signatures := map[string][]byte{
+		"png":  {0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a},
+		"jpeg": {0xff, 0xd8, 0xff},
+		"JPG":  {0xff, 0xd8, 0xff},
+		"gif":  []byte("GIF89a"),
+		"pdf":  []byte("%PDF-"),
+		"zip":  {0x50, 0x4b, 0x03, 0x04},
+		"gzip": {0x1f, 0x8b},
+		"bmp":  []byte("BM"),
+	}
+	for fileType, signature := range signatures {
+		t.Run(fileType, func(t *testing.T) {
+			result := fastrand.Randomizer([]byte("{RAND;32;MAGIC;" + fileType + "}"))
+			if !bytes.HasPrefix(result, signature) {
+				t.Errorf("Expected %x to start with %x", result, signature)
+			}
+			if len(result) != len(signature)+32 {
+				t.Errorf("Expected %d bytes, got %d", len(signature)+32, len(result))
+			}
+		})
+	}
+
+	t.Run("Unknown", func(t *testing.T) {
+		if result := fastrand.Randomizer([]byte("{RAND;12;MAGIC;exe}")); len(result) != 12 {
+			t.Errorf("Expected only 12 random bytes, got %d", len(result))
+		}
+	})
+
+	t.Run("ByteSize", func(t *testing.T) {
+		if result := fastrand.Randomizer([]byte("{RAND;1k;MAGIC;pdf}")); len(result) != 5+1024 {
+			t.Errorf("Expected the signature and 1024 bytes, got %d", len(result))
+		}
+	})
+}
+
+func TestCSVRowsKeyword(t *testing.T) {
+	parse := func(t *testing.T, data string) [][]string {
+		t.Helper()
+		records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+		if err != nil {
+			t.Fatalf("Expected valid CSV, got %q: %v", data, err)
+		}
+		return records
+	}
+
+	t.Run("Rows", func(t *testing.T) {
+		records := parse(t, fastrand.RandomizerString("{RAND;3;CSVROWS;DIGIT,EMAIL,ABL}"))
+		if len(records) != 3 {
+			t.Fatalf("Expected 3 rows, got %d", len(records))
+		}
+		for _, record := range records {
+			if len(record) != 3 {
+				t.Fatalf("Expected 3 columns, got %q", record)
+			}
+			if !regexp.MustCompile(`^\d+$`).MatchString(record[0]) || !strings.Contains(record[1], "@") {
+				t.Errorf("Expected DIGIT and EMAIL columns, got %q", record)
+			}
+		}
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		records := parse(t, fastrand.RandomizerString("{RAND;2;CSVROWS;UUID,IPV4;header}"))
+		if len(records) != 3 || !slices.Equal(records[0], []string{"UUID", "IPV4"}) {
+			t.Errorf("Expected a header and 2 rows, got %q", records)
+		}
+	})
+
+	t.Run("Quoting", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithCustomCharset("TRICKY", []byte(",\"\n")))
+		for i := 0; i < 50; i++ {
+			records := parse(t, engine.RandomizerString("{RAND;4;CSVROWS;TRICKY,DIGIT}"))
+			if len(records) != 4 {
+				t.Fatalf("Expected 4 rows, got %d", len(records))
+			}
+			for _, record := range records {
+				if len(record) != 2 || strings.Trim(record[0], ",\"\n") != "" {
+					t.Fatalf("Expected quoted fields to round-trip, got %q", record)
+				}
+			}
+		}
+	})
+
+	t.Run("NoColumns", func(t *testing.T) {
+		if result := fastrand.RandomizerString("{RAND;3;CSVROWS}"); result != "" {
+			t.Errorf("Expected no output without columns, got %q", result)
+		}
+	})
+}
+
+func TestStepKeyword(t *testing.T) {
+	testCases := []struct {
+		arg     string
+		members []int64
+	}{
+		{"0;100;10", []int64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}},
+		{"100;0;-25", []int64{100, 75, 50, 25, 0}},
+		{"1;10;4", []int64{1, 5, 9}},
+		{"-3;3;3", []int64{-3, 0, 3}},
+		{"7;7;5", []int64{7}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.arg, func(t *testing.T) {
+			seen := make(map[int64]bool)
+			for i := 0; i < 500; i++ {
+				result := fastrand.RandomizerString("{RAND;;STEP;" + tc.arg + "}")
+				v, err := strconv.ParseInt(result, 10, 64)
+				if err != nil || !slices.Contains(tc.members, v) {
+					t.Fatalf("Expected a member of %v, got %q", tc.members, result)
+				}
+				seen[v] = true
+			}
+			if len(seen) != len(tc.members) {
+				t.Errorf("Expected every member of %v to be picked, got %v", tc.members, seen)
+			}
+		})
+	}
+
+	t.Run("Extremes", func(t *testing.T) {
+		result := fastrand.RandomizerString("{RAND;;STEP;-9223372036854775808;9223372036854775807;9223372036854775807}")
+		if !slices.Contains([]string{"-9223372036854775808", "-1", "9223372036854775806"}, result) {
+			t.Errorf("Expected a member of the full-range progression, got %q", result)
+		}
+
+		// Every int64 value, forwards and backwards: the count wraps to
+		// zero unless it is saturated.
+		for _, arg := range []string{"-9223372036854775808;9223372036854775807;1", "9223372036854775807;-9223372036854775808;-1"} {
+			var negative, positive bool
+			for i := 0; i < 100; i++ {
+				v, err := strconv.ParseInt(fastrand.RandomizerString("{RAND;;STEP;"+arg+"}"), 10, 64)
+				if err != nil {
+					t.Fatalf("Expected an int64 for %s: %v", arg, err)
+				}
+				negative, positive = negative || v < 0, positive || v >= 0
+			}
+			if !negative || !positive {
+				t.Errorf("Expected values across the whole range for %s", arg)
+			}
+		}
+		if bits := fastrand.EntropyBits([]byte("{RAND;;STEP;-9223372036854775808;9223372036854775807;1}")); bits != 64 {
+			t.Errorf("Expected 64 bits for the full range, got %v", bits)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithPreserveOnError())
+		for _, arg := range []string{"0;100;0", "0;100;-10", "100;0;10", "0;10", "a;10;1"} {
+			if result := fastrand.RandomizerString("{RAND;;STEP;" + arg + "}"); result != "" {
+				t.Errorf("Expected no output for %q, got %q", arg, result)
+			}
+			template := "{RAND;;STEP;" + arg + "}"
+			if result := engine.RandomizerString(template); result != template {
+				t.Errorf("Expected WithPreserveOnError to keep %q, got %q", template, result)
+			}
+		}
+	})
+}
+
+func TestNumericKeywordFormatting(t *testing.T) {
+	testCases := []struct {
+		n    string
+		want string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"+7", "7"},
+		{"-1", "-1"},
+		{"007", "7"},
+		{"-9223372036854775808", "-9223372036854775808"},
+		{"9223372036854775807", "9223372036854775807"},
+		{"-9223372036854775807", "-9223372036854775807"},
+	}
+	// Every digit-count boundary: 9 and 10, 99 and 100, and so on, with
+	// their negatives.
+	for digits := 1; digits <= 18; digits++ {
+		nines, power := strings.Repeat("9", digits), "1"+strings.Repeat("0", digits)
+		testCases = append(testCases,
+			struct{ n, want string }{nines, nines},
+			struct{ n, want string }{power, power},
+			struct{ n, want string }{"-" + nines, "-" + nines},
+			struct{ n, want string }{"-" + power, "-" + power},
+		)
+	}
+	for _, tc := range testCases {
+		if result := fastrand.RandomizerString("{RAND;;STEP;" + tc.n + ";" + tc.n + ";1}"); result != tc.want {
+			t.Errorf("STEP %s: expected %s, got %q", tc.n, tc.want, result)
+		}
+	}
+
+	for i := 0; i < 200; i++ {
+		status := fastrand.RandomizerString("{RAND;;STATUS}")
+		if code, err := strconv.Atoi(status); err != nil || code < 100 || code > 599 || strconv.Itoa(code) != status {
+			t.Fatalf("Expected a canonical status code, got %q", status)
+		}
+		pid := fastrand.RandomizerString("{RAND;;PID}")
+		if n, err := strconv.ParseInt(pid, 10, 64); err != nil || n < 1 || strconv.FormatInt(n, 10) != pid {
+			t.Fatalf("Expected a canonical PID, got %q", pid)
+		}
+	}
+	if pid := fastrand.RandomizerString("{RAND;;PID;self}"); pid != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Expected the current PID, got %q", pid)
+	}
+}
+
+func TestDNSKeyword(t *testing.T) {
+	mxRegex := regexp.MustCompile(`^(0|[1-5]0) [a-z0-9]+\.[a-z]{3,10}\.[a-z]{2,3}$`)
+	txtRegex := regexp.MustCompile(`^"[A-Za-z0-9 =\-_.:]{12}"$`)
+
+	validators := map[string]func(string) bool{
+		"A":    func(v string) bool { ip := net.ParseIP(v); return ip != nil && ip.To4() != nil },
+		"aaaa": func(v string) bool { ip := net.ParseIP(v); return ip != nil && strings.Contains(v, ":") },
+		"MX":   mxRegex.MatchString,
+		"TXT":  txtRegex.MatchString,
+		"SRV":  func(v string) bool { ip := net.ParseIP(v); return ip != nil && ip.To4() != nil },
+	}
+	for recordType, valid := range validators {
+		t.Run(recordType, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				if value := fastrand.RandomizerString("{RAND;12;DNS;" + recordType + "}"); !valid(value) {
+					t.Fatalf("Expected a valid %s value, got %q", recordType, value)
+				}
+			}
+		})
+	}
+
+	t.Run("MXPreference", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i := 0; i < 300; i++ {
+			preference, _, _ := strings.Cut(fastrand.RandomizerString("{RAND;;DNS;MX}"), " ")
+			seen[preference] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("Expected varying MX preferences, got %v", seen)
+		}
+	})
+}
+
+func TestJSONPointerKeyword(t *testing.T) {
+	pointerRegex := regexp.MustCompile(`^(/([^/~]|~[01])*)*$`)
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	escape := strings.NewReplacer("~", "~0", "/", "~1")
+
+	sawIndex, sawEscape := false, false
+	for i := 0; i < 300; i++ {
+		pointer := fastrand.RandomizerString("{RAND;4;JSONPOINTER}")
+		if !pointerRegex.MatchString(pointer) {
+			t.Fatalf("Expected a valid JSON Pointer, got %q", pointer)
+		}
+		segments := strings.Split(pointer, "/")[1:]
+		if len(segments) != 4 {
+			t.Fatalf("Expected 4 segments, got %q", pointer)
+		}
+		for _, segment := range segments {
+			if segment == "" {
+				t.Fatalf("Expected non-empty segments, got %q", pointer)
+			}
+			if _, err := strconv.Atoi(segment); err == nil {
+				sawIndex = true
+			}
+			if strings.Contains(segment, "~") {
+				sawEscape = true
+			}
+			if key := unescape.Replace(segment); escape.Replace(key) != segment {
+				t.Fatalf("Expected segment %q to round-trip through its key %q", segment, key)
+			}
+		}
+	}
+	if !sawIndex || !sawEscape {
+		t.Errorf("Expected array indices and escaped keys, got index=%v escape=%v", sawIndex, sawEscape)
+	}
+}
+
+func TestBase58Keyword(t *testing.T) {
+	base58Regex := regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]*$`)
+	for _, length := range []int{1, 25, 34, 99} {
+		for i := 0; i < 50; i++ {
+			result := fastrand.RandomizerString(fmt.Sprintf("{RAND;%d;BASE58}", length))
+			if len(result) != length {
+				t.Fatalf("Expected %d characters, got %d in %q", length, len(result), result)
+			}
+			if !base58Regex.MatchString(result) || strings.ContainsAny(result, "0OIl") {
+				t.Fatalf("Expected only base58 characters, got %q", result)
+			}
+		}
+	}
+	if got := len(fastrand.CharsBase58); got != 58 {
+		t.Errorf("Expected 58 characters in CharsBase58, got %d", got)
+	}
+}
+
+// unquoteShell reads a single POSIX shell word made of single-quoted,
+// double-quoted and backslash-escaped parts.
+func unquoteShell(word string) (string, bool) {
+	var out strings.Builder
+	for i := 0; i < len(word); i++ {
+		switch c := word[i]; c {
+		case '\'':
+			end := strings.IndexByte(word[i+1:], '\'')
+			if end < 0 {
+				return "", false
+			}
+			out.WriteString(word[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			for i++; ; i++ {
+				if i >= len(word) {
+					return "", false
+				}
+				if word[i] == '"' {
+					break
+				}
+				if word[i] == '\\' && i+1 < len(word) && strings.IndexByte("\"\\$`", word[i+1]) >= 0 {
+					i++
+				} else if strings.IndexByte("$`", word[i]) >= 0 {
+					return "", false
+				}
+				out.WriteByte(word[i])
+			}
+		case '\\':
+			if i+1 >= len(word) {
+				return "", false
+			}
+			i++
+			out.WriteByte(word[i])
+		case ' ', '\t', '\n', '$', '`':
+			return "", false
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), true
+}
+
+func unquoteSQL(literal string) (string, bool) {
+	if len(literal) < 2 || literal[0] != '\'' || literal[len(literal)-1] != '\'' {
+		return "", false
+	}
+	inner := literal[1 : len(literal)-1]
+	if strings.Count(strings.ReplaceAll(inner, "''", ""), "'") > 0 {
+		return "", false
+	}
+	return strings.ReplaceAll(inner, "''", "'"), true
+}
+
+func TestQuotedKeyword(t *testing.T) {
+	quotedChars := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 '\"\\$`"
+	unquoters := map[string]func(string) (string, bool){
+		"single": unquoteShell,
+		"double": unquoteShell,
+		"SQL":    unquoteSQL,
+		"sql":    unquoteSQL,
+		"":       unquoteShell,
+	}
+	for style, unquote := range unquoters {
+		t.Run(style, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				result := fastrand.RandomizerString("{RAND;10;QUOTED;" + style + "}")
+				inner, ok := unquote(result)
+				if !ok || len(inner) != 10 || strings.Trim(inner, quotedChars) != "" {
+					t.Fatalf("Expected %s quoting of 10 characters, got %q (inner %q)", style, result, inner)
+				}
+			}
+		})
+	}
+
+	// The same seed draws the same inner value whatever the quoting style.
+	t.Run("SameInner", func(t *testing.T) {
+		for seed := uint64(0); seed < 50; seed++ {
+			var inners []string
+			for _, style := range []string{"single", "double", "sql"} {
+				result := fastrand.NewEngine(fastrand.WithSeed(seed)).RandomizerString("{RAND;16;QUOTED;" + style + "}")
+				inner, _ := unquoters[style](result)
+				inners = append(inners, inner)
+			}
+			if inners[0] != inners[1] || inners[0] != inners[2] {
+				t.Fatalf("Expected every style to quote the same value, got %q", inners)
+			}
+		}
+	})
+}
+
+func TestYAMLValKeyword(t *testing.T) {
+	kinds := map[string]func(any) bool{
+		"int":    func(v any) bool { _, ok := v.(int); return ok },
+		"float":  func(v any) bool { _, ok := v.(float64); return ok },
+		"bool":   func(v any) bool { _, ok := v.(bool); return ok },
+		"string": func(v any) bool { _, ok := v.(string); return ok },
+		"":       func(v any) bool { return v != nil },
+	}
+	for typ, isKind := range kinds {
+		t.Run(typ, func(t *testing.T) {
+			for i := 0; i < 300; i++ {
+				result := fastrand.RandomizerString("{RAND;;YAMLVAL;" + typ + "}")
+				var value any
+				if err := yaml.Unmarshal([]byte("v: "+result), &map[string]any{}); err != nil {
+					t.Fatalf("Expected valid YAML, got %q: %v", result, err)
+				}
+				if err := yaml.Unmarshal([]byte(result), &value); err != nil || !isKind(value) {
+					t.Fatalf("Expected a YAML %s, got %q decoded as %T", typ, result, value)
+				}
+			}
+		})
+	}
+
+	t.Run("Lookalikes", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i := 0; i < 2000; i++ {
+			result := fastrand.RandomizerString("{RAND;;YAMLVAL;string}")
+			var value string
+			if err := yaml.Unmarshal([]byte(result), &value); err != nil {
+				t.Fatalf("Expected a YAML string, got %q: %v", result, err)
+			}
+			seen[value] = true
+			if slices.Contains([]string{"yes", "No", "on", "123", "null", "~", ""}, value) && !strings.HasPrefix(result, `"`) {
+				t.Fatalf("Expected %q to be quoted, got %s", value, result)
+			}
+		}
+		if !seen["yes"] || !seen["123"] {
+			t.Errorf("Expected lookalike strings such as yes and 123 to appear")
+		}
+	})
+}
+
+func TestLatencyKeyword(t *testing.T) {
+	sample := func(t *testing.T, template string, n int) []float64 {
+		engine := fastrand.NewEngine(fastrand.WithSeed(1))
+		values := make([]float64, n)
+		for i := range values {
+			result := engine.RandomizerString(template)
+			v, err := strconv.ParseFloat(result, 64)
+			if err != nil || v < 0 {
+				t.Fatalf("Expected a non-negative latency, got %q", result)
+			}
+			values[i] = v
+		}
+		slices.Sort(values)
+		return values
+	}
+
+	tests := []struct {
+		template string
+		median   float64
+	}{
+		{"{RAND;;LATENCY}", 100},
+		{"{RAND;;LATENCY;200;50}", 200},
+		{"{RAND;;LATENCY;200;400}", 200},
+		{"{RAND;;LATENCY;40;10;us}", 40000},
+		{"{RAND;;LATENCY;1500;;S}", 1.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			values := sample(t, tt.template, 5000)
+			if median := values[len(values)/2]; math.Abs(median-tt.median) > tt.median*0.05 {
+				t.Errorf("Expected a median near %v, got %v", tt.median, median)
+			}
+		})
+	}
+
+	t.Run("Skewed", func(t *testing.T) {
+		values := sample(t, "{RAND;;LATENCY;200;50}", 5000)
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		// The log-normal mean lies above the median.
+		if mean := sum / float64(len(values)); mean <= 200 || mean > 215 {
+			t.Errorf("Expected a mean slightly above 200, got %v", mean)
+		}
+	})
+
+	for _, template := range []string{"{RAND;;LATENCY;0}", "{RAND;;LATENCY;-5}", "{RAND;;LATENCY;100;-1}", "{RAND;;LATENCY;abc}", "{RAND;;LATENCY;100;10;min}", "{RAND;;LATENCY;1;2;ms;4}"} {
+		if result := fastrand.RandomizerString(template); result != "" {
+			t.Errorf("Expected %s to give nothing, got %q", template, result)
+		}
+	}
+}
+
+func TestMarkdownKeyword(t *testing.T) {
+	word := `[a-z]+`
+	patterns := map[string]*regexp.Regexp{
+		"heading":  regexp.MustCompile(`^#{1,6} ` + word + `( ` + word + `){2}$`),
+		"list":     regexp.MustCompile(`^((- ` + word + `( ` + word + `){2}\n){2,5}|(\d\. ` + word + `( ` + word + `){2}\n){2,5})$`),
+		"emphasis": regexp.MustCompile(`^[a-z* ]+$`),
+		"link":     regexp.MustCompile(`^\[` + word + `( ` + word + `){2}\]\((https://[^)\s]+)\)$`),
+	}
+	for kind, pattern := range patterns {
+		t.Run(kind, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				result := fastrand.RandomizerString("{RAND;3;MARKDOWN;" + kind + "}")
+				match := pattern.FindStringSubmatch(result)
+				if match == nil {
+					t.Fatalf("Expected a Markdown %s, got %q", kind, result)
+				}
+				switch kind {
+				case "list":
+					lines := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+					for n, line := range lines {
+						if !strings.HasPrefix(line, "- ") && !strings.HasPrefix(line, strconv.Itoa(n+1)+". ") {
+							t.Fatalf("Expected item %d to be numbered in order, got %q", n+1, result)
+						}
+					}
+				case "emphasis":
+					if strings.Count(result, "**") != 2 || strings.Count(result, "*") != 6 || len(strings.Fields(result)) != 3 {
+						t.Fatalf("Expected one bold and one italic word, got %q", result)
+					}
+				case "link":
+					if u, err := url.Parse(match[len(match)-1]); err != nil || u.Scheme != "https" || u.Host == "" {
+						t.Fatalf("Expected a valid https link, got %q", result)
+					}
+				}
+			}
+		})
+	}
+
+	if result := fastrand.RandomizerString("{RAND;1;MARKDOWN;emphasis}"); !regexp.MustCompile(`^\*\*[a-z]+\*\*$`).MatchString(result) {
+		t.Errorf("Expected a single bold word, got %q", result)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		result := fastrand.RandomizerString("{RAND;2;MARKDOWN}")
+		for kind, token := range map[string]string{"heading": "# ", "list": "\n", "link": "](https://"} {
+			if strings.Contains(result, token) {
+				seen[kind] = true
+			}
+		}
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected a mix of Markdown kinds by default, got %v", seen)
+	}
+}
+
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+func (n xmlNode) depth() int {
+	deepest := 0
+	for _, child := range n.Nodes {
+		deepest = max(deepest, child.depth())
+	}
+	return deepest + 1
+}
+
+func TestXMLKeyword(t *testing.T) {
+	ncName := regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+	var checkNames func(t *testing.T, n xmlNode, result string)
+	checkNames = func(t *testing.T, n xmlNode, result string) {
+		names := []string{n.XMLName.Local}
+		for _, attr := range n.Attrs {
+			names = append(names, attr.Name.Local)
+		}
+		for _, name := range names {
+			if !ncName.MatchString(name) || strings.HasPrefix(strings.ToLower(name), "xml") {
+				t.Fatalf("Expected NCNames, got %q in %s", name, result)
+			}
+		}
+		for _, child := range n.Nodes {
+			checkNames(t, child, result)
+		}
+	}
+
+	tests := []struct {
+		arg      string
+		maxDepth int
+	}{
+		{"", 2},
+		{"1", 1},
+		{"3", 3},
+		{"99", 5},
+		{"0", 1},
+		{"deep", 2},
+	}
+	for _, tt := range tests {
+		t.Run("Depth"+tt.arg, func(t *testing.T) {
+			deepest := 0
+			for i := 0; i < 300; i++ {
+				result := fastrand.RandomizerString("{RAND;;XML;" + tt.arg + "}")
+				var root xmlNode
+				if err := xml.Unmarshal([]byte(result), &root); err != nil {
+					t.Fatalf("Expected well-formed XML, got %q: %v", result, err)
+				}
+				checkNames(t, root, result)
+				if d := root.depth(); d > tt.maxDepth {
+					t.Fatalf("Expected a depth of at most %d, got %d in %s", tt.maxDepth, d, result)
+				}
+				deepest = max(deepest, root.depth())
+			}
+			if deepest != tt.maxDepth {
+				t.Errorf("Expected the depth to reach %d, got %d", tt.maxDepth, deepest)
+			}
+		})
+	}
+
+	t.Run("Escaping", func(t *testing.T) {
+		var escaped bool
+		for i := 0; i < 300 && !escaped; i++ {
+			result := fastrand.RandomizerString("{RAND;;XML;3}")
+			escaped = strings.Contains(result, "&amp;") || strings.Contains(result, "&lt;") || strings.Contains(result, "&#34;")
+		}
+		if !escaped {
+			t.Error("Expected special characters in attributes and text to be escaped")
+		}
+	})
+}
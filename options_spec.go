@@ -0,0 +1,181 @@
+package fastrand
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseOptions turns a spec such as "defaultLength=32,disable=UUID;EMAIL"
+// into options for NewEngine, so engines can be configured from environment
+// variables or flags. Entries are comma-separated key=value pairs; list
+// values are separated by semicolons. Keys are case-insensitive:
+//
+//	defaultLength, minLength, maxLength, maxByteLength, maxEmailLength,
+//	bufferHint                         integers
+//	ranges, keywordChoices,
+//	lengthChoices                      booleans
+//	strictRanges, preserveOnError      booleans, enabled when true
+//	disable, enable                    keyword lists
+//	alias                              list of ALIAS:KEYWORD pairs
+//	inputEncoding                      list of none, url, html
+//	outputEncoding                     one of none, url, html, csv
+//	prefix, suffix                     literal text
+//	source                             fast or secure
+//	seed                               unsigned integer for a fixed PCG source
+//
+// Unknown keys and malformed values are reported with their byte offset in
+// spec.
+func ParseOptions(spec string) ([]Option, error) {
+	var opts []Option
+	offset := 0
+	for _, entry := range strings.Split(spec, ",") {
+		start := offset
+		offset += len(entry) + 1
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("fastrand: option %q at offset %d: missing '='", entry, start)
+		}
+		opt, err := parseOption(strings.TrimSpace(key), strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("fastrand: option %q at offset %d: %w", entry, start, err)
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+func parseOption(key, value string) (Option, error) {
+	switch strings.ToLower(key) {
+	case "defaultlength":
+		return intOption(value, WithDefaultLength)
+	case "minlength":
+		return intOption(value, WithMinLength)
+	case "maxlength":
+		return intOption(value, WithMaxLength)
+	case "maxbytelength":
+		return intOption(value, WithMaxByteLength)
+	case "maxemaillength":
+		return intOption(value, WithMaxEmailLength)
+	case "bufferhint":
+		return intOption(value, WithBufferHint)
+	case "ranges":
+		return boolOption(value, WithRanges)
+	case "keywordchoices":
+		return boolOption(value, WithKeywordChoices)
+	case "lengthchoices":
+		return boolOption(value, WithLengthChoices)
+	case "strictranges":
+		return flagOption(value, WithStrictRanges)
+	case "preserveonerror":
+		return flagOption(value, WithPreserveOnError)
+	case "disable":
+		return WithDisabledKeywords(splitList(value)...), nil
+	case "enable":
+		return WithEnabledKeywords(splitList(value)...), nil
+	case "alias":
+		var aliases []Option
+		for _, pair := range splitList(value) {
+			alias, target, ok := strings.Cut(pair, ":")
+			alias, target = strings.TrimSpace(alias), strings.TrimSpace(target)
+			if !ok || alias == "" || target == "" {
+				return nil, fmt.Errorf("invalid alias %q", pair)
+			}
+			aliases = append(aliases, WithKeywordAlias(alias, target))
+		}
+		return func(e *FastEngine) {
+			for _, opt := range aliases {
+				opt(e)
+			}
+		}, nil
+	case "inputencoding":
+		var encoding RandomizerEncoding
+		for _, name := range splitList(value) {
+			enc, err := parseEncoding(name)
+			if err != nil || enc == RandomizerEncodingCSV {
+				return nil, fmt.Errorf("invalid input encoding %q", name)
+			}
+			encoding |= enc
+		}
+		return WithInputEncoding(encoding), nil
+	case "outputencoding":
+		encoding, err := parseEncoding(value)
+		if err != nil {
+			return nil, err
+		}
+		return WithOutputEncoding(encoding), nil
+	case "prefix":
+		return WithGlobalPrefix(value), nil
+	case "suffix":
+		return WithGlobalSuffix(value), nil
+	case "source":
+		switch strings.ToLower(value) {
+		case "fast":
+			return WithSource(FastSource), nil
+		case "secure":
+			return WithSource(SecureSource), nil
+		}
+		return nil, fmt.Errorf("invalid source %q", value)
+	case "seed":
+		seed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed %q", value)
+		}
+		return WithSeed(seed), nil
+	}
+	return nil, fmt.Errorf("unknown key %q", key)
+}
+
+func intOption(value string, option func(int) Option) (Option, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid integer %q", value)
+	}
+	return option(n), nil
+}
+
+func boolOption(value string, option func(bool) Option) (Option, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid boolean %q", value)
+	}
+	return option(b), nil
+}
+
+func flagOption(value string, option func() Option) (Option, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid boolean %q", value)
+	}
+	if !b {
+		return func(*FastEngine) {}, nil
+	}
+	return option(), nil
+}
+
+func parseEncoding(name string) (RandomizerEncoding, error) {
+	switch strings.ToLower(name) {
+	case "none", "":
+		return RandomizerEncodingNone, nil
+	case "url":
+		return RandomizerEncodingURL, nil
+	case "html":
+		return RandomizerEncodingHTML, nil
+	case "csv":
+		return RandomizerEncodingCSV, nil
+	}
+	return 0, fmt.Errorf("invalid encoding %q", name)
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package fastrand_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestParseOptions(t *testing.T) {
+	t.Run("Representative", func(t *testing.T) {
+		opts, err := fastrand.ParseOptions("defaultLength=32, disable=UUID;email ,outputEncoding=url,prefix=>>,seed=42")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		engine := fastrand.NewEngine(opts...)
+		if result := engine.RandomizerString("{RAND;;DIGIT}"); !regexp.MustCompile(`^>>[0-9]{32}$`).MatchString(result) {
+			t.Errorf("Expected the prefix and a 32-digit default length, got %q", result)
+		}
+		if result := engine.RandomizerString("{RAND;;UUID}"); len(result) != 2+32 || uuidRegex.MatchString(result[2:]) {
+			t.Errorf("Expected the disabled UUID to fall back to the default charset, got %q", result)
+		}
+		if err := engine.Validate([]byte("{RAND;;EMAIL}")); err == nil {
+			t.Error("Expected EMAIL to be disabled")
+		}
+		if result := engine.RandomizerString("a b"); result != ">>a+b" {
+			t.Errorf("Expected URL output encoding, got %q", result)
+		}
+	})
+
+	t.Run("SeedIsDeterministic", func(t *testing.T) {
+		generate := func() string {
+			opts, err := fastrand.ParseOptions("seed=42")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			return fastrand.NewEngine(opts...).RandomizerString("{RAND;32;ABR}{RAND;;HEX}")
+		}
+		if a, b := generate(), generate(); a != b {
+			t.Errorf("Expected equal seeds to give equal output, got %q and %q", a, b)
+		}
+	})
+
+	t.Run("Alias", func(t *testing.T) {
+		opts, err := fastrand.ParseOptions("alias=ID:UUID; num : DIGIT")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		engine := fastrand.NewEngine(opts...)
+		if result := engine.RandomizerString("{RAND;;ID}"); !uuidRegex.MatchString(result) {
+			t.Errorf("Expected ID to alias UUID, got %q", result)
+		}
+		if result := engine.RandomizerString("{RAND;8;NUM}"); !regexp.MustCompile(`^[0-9]{8}$`).MatchString(result) {
+			t.Errorf("Expected NUM to alias DIGIT, got %q", result)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if opts, err := fastrand.ParseOptions(""); err != nil || len(opts) != 0 {
+			t.Errorf("Expected no options, got %d, %v", len(opts), err)
+		}
+	})
+
+	errorCases := []struct {
+		spec   string
+		offset string
+	}{
+		{"defaultLength=32,colour=blue", "offset 17"},
+		{"defaultLength=abc", "offset 0"},
+		{"ranges=maybe", "offset 0"},
+		{"maxLength=50,disable", "offset 13"},
+		{"inputEncoding=url;csv", "offset 0"},
+		{"source=lava", "offset 0"},
+		{"seed=-1", "offset 0"},
+		{"seed=1,alias=ID", "offset 7"},
+		{"alias=:UUID", "offset 0"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			_, err := fastrand.ParseOptions(tc.spec)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.offset) {
+				t.Errorf("Expected the error to mention %s, got %v", tc.offset, err)
+			}
+		})
+	}
+}
@@ -25,6 +25,9 @@ var (
 	CharsAlphabet       = append(CharsAlphabetLower, CharsAlphabetUpper...)
 	CharsAlphabetDigits = append(CharsAlphabet, CharsDigits...)
 	CharsAll            = append(CharsAlphabetDigits, CharsSymbolChars...)
+	// CharsBase58 is the Bitcoin base58 alphabet, which leaves out 0, O, I
+	// and l.
+	CharsBase58 = CharsList("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
 )
 
 type number interface {
@@ -123,6 +126,32 @@ func Bytes(length int) []byte {
 	return b
 }
 
+var BoundaryBytes = []byte{0x00, 0x7F, 0x80, 0xFF}
+
+// BytesBiased returns length random bytes where each byte is, with pct percent
+// probability, one of the bias bytes and otherwise uniformly random. pct is
+// clamped to [0, 100]; an empty bias yields plain uniform bytes.
+func BytesBiased(length int, bias []byte, pct int) []byte {
+	if length < 0 {
+		panic("fastrand: length cannot be negative")
+	}
+	return biasedBytes(FastSource, length, bias, pct)
+}
+
+func biasedBytes(r Source, length int, bias []byte, pct int) []byte {
+	b := randBytes(r, length)
+	if len(bias) == 0 {
+		return b
+	}
+	pct = max(0, min(pct, 100))
+	for i := range b {
+		if r.Intn(100) < pct {
+			b[i] = bias[r.Intn(len(bias))]
+		}
+	}
+	return b
+}
+
 func Hex(length int) string {
 	return fmt.Sprintf("%x", Bytes(length))
 }
@@ -135,6 +164,9 @@ func SecureHex(length int) (string, error) {
 	return fmt.Sprintf("%x", bytes), nil
 }
 
+// String returns length characters drawn uniformly from charset. Indices come
+// from IntN, which uses rejection sampling, so charsets whose size does not
+// divide the generator range (such as the 62 alphanumerics) are not biased.
 func String(length int, charset CharsList) string {
 	if length <= 0 {
 		panic("fastrand: length must be positive")
@@ -178,6 +210,44 @@ func ChoiceKey[T comparable, V any](items map[T]V) T {
 	panic("unreachable")
 }
 
+// WeightedChoice selects an element with probability proportional to its
+// weight. Non-positive weights are never chosen. It panics if items is empty,
+// if the slices differ in length or if no weight is positive.
+func WeightedChoice[T any](items []T, weights []float64) T {
+	return weightedChoice(items, weights, pcgSrc.Float64)
+}
+
+func weightedChoice[T any](items []T, weights []float64, random func() float64) T {
+	if len(items) == 0 {
+		panic("fastrand: cannot choose from an empty slice")
+	}
+	if len(items) != len(weights) {
+		panic("fastrand: items and weights must have the same length")
+	}
+	var total float64
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if total <= 0 {
+		panic("fastrand: weights must contain a positive value")
+	}
+	target := random() * total
+	last := 0
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if target < w {
+			return items[i]
+		}
+		target -= w
+		last = i
+	}
+	return items[last]
+}
+
 func ChoiceItemNullable[T any](slice []T) (*T, error) {
 	if len(slice) == 0 {
 		return nil, errors.New("fastrand: cannot choose from an empty slice")
@@ -230,6 +300,22 @@ func IPv6() net.IP {
 	return Bytes(net.IPv6len)
 }
 
+// MAC returns a random unicast MAC address. A 3-byte oui, when given, is kept
+// as the vendor prefix and only the NIC-specific bytes are randomized.
+func MAC(oui []byte) net.HardwareAddr {
+	return generateMAC(FastSource, oui)
+}
+
+func generateMAC(r Source, oui []byte) net.HardwareAddr {
+	mac := net.HardwareAddr(randBytes(r, 6))
+	if len(oui) == 3 {
+		copy(mac, oui)
+	} else {
+		mac[0] &^= 1
+	}
+	return mac
+}
+
 func Float64() float64 {
 	return pcgSrc.Float64()
 }
@@ -315,6 +401,8 @@ func SecureBytes(length int) ([]byte, error) {
 	return b, nil
 }
 
+// SecureString is String backed by the ChaCha8 source. It is equally free of
+// modulo bias and is the one to use for tokens and passwords.
 func SecureString(length int, charset CharsList) (string, error) {
 	if length <= 0 {
 		return "", errors.New("fastrand: length must be positive")
@@ -1,14 +1,19 @@
 package fastrand_test
 
 import (
+	"bytes"
 	crand "crypto/rand"
 	"fmt"
 	"github.com/SyNdicateFoundation/fastrand"
+	"math"
+	"strconv"
 	"testing"
 )
 
 var benchmarkErr error
 
+var benchmarkSink []byte
+
 func BenchmarkIntnFastRand(b *testing.B) {
 	b.ReportAllocs()
 	var res int
@@ -173,6 +178,13 @@ func BenchmarkEngine(b *testing.B) {
 	}
 }
 
+func BenchmarkNewEngine(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fastrand.NewEngine()
+	}
+}
+
 func BenchmarkRandomizer(b *testing.B) {
 	payload := []byte("User: {RAND;10-20;ABL,ABU} | Session: {RANDOM;32;HEX} | ID: {RAND;UUID,HEX} | IP: {RAND;IPV4} | Data: {RAND;50-99} --- End")
 	b.ReportAllocs()
@@ -181,3 +193,94 @@ func BenchmarkRandomizer(b *testing.B) {
 		_ = fastrand.Randomizer(payload)
 	}
 }
+
+func BenchmarkGenerator(b *testing.B) {
+	generator := fastrand.NewGenerator()
+	template := []byte("User: {RAND;10-20;ABL,ABU} | Session: {RANDOM;32;DIGIT} | Data: {RAND;50-99}")
+	dst := make([]byte, 256)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := generator.Generate(dst, template); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNumericKeywords(b *testing.B) {
+	template := []byte("{RAND;;STATUS} {RAND;;PID} {RAND;;STEP;-1000000;1000000;7} {RAND;;BASEN;10;99999999} {RAND;;SEMVER}")
+
+	b.Run("Template", func(b *testing.B) {
+		generator := fastrand.NewGenerator()
+		dst := make([]byte, 128)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := generator.Generate(dst, template); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// Itoa is how the keywords formatted numbers before, through an
+	// intermediate string; AppendInt is how they write into the output
+	// buffer now. Both format the same values.
+	values := []int64{404, 4194304, -999999, 99999999, 0, 19, math.MinInt64, math.MaxInt64}
+
+	b.Run("Itoa", func(b *testing.B) {
+		dst := make([]byte, 0, 128)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dst = dst[:0]
+			for _, n := range values {
+				dst = append(dst, strconv.Itoa(int(n))...)
+			}
+		}
+		benchmarkSink = dst
+	})
+
+	b.Run("AppendInt", func(b *testing.B) {
+		dst := make([]byte, 0, 128)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dst = dst[:0]
+			for _, n := range values {
+				dst = strconv.AppendInt(dst, n, 10)
+			}
+		}
+		benchmarkSink = dst
+	})
+}
+
+func BenchmarkRandomizerChoices(b *testing.B) {
+	payloads := map[string][]byte{
+		"NoComma":        []byte("{RAND;16;ABL}{RAND;8;DIGIT}{RAND;32;ABR}"),
+		"KeywordChoices": []byte("{RAND;16;ABL,ABU,NOPE}{RAND;8;DIGIT,ABR}"),
+		"LengthChoices":  []byte("{RAND;8,16,32;ABL}{RAND;4,200;DIGIT}"),
+	}
+	generator := fastrand.NewGenerator()
+	dst := make([]byte, 256)
+	for name, payload := range payloads {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = generator.Generate(dst, payload)
+			}
+		})
+	}
+}
+
+func BenchmarkRandomizerLargeTags(b *testing.B) {
+	payload := bytes.Repeat([]byte("{RAND;99;BYTES}"), 10)
+	engines := map[string]*fastrand.FastEngine{
+		"Default":    fastrand.NewEngine(),
+		"BufferHint": fastrand.NewEngine(fastrand.WithBufferHint(10 * 99)),
+	}
+	for name, engine := range engines {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = engine.Randomizer(payload)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package fastrand_test
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/SyNdicateFoundation/fastrand"
 	"net"
@@ -797,3 +798,117 @@ func TestMustSecureUUID(t *testing.T) {
 		_ = fastrand.MustSecureUUID()
 	})
 }
+
+func charDistribution(s string, charset fastrand.CharsList) (minCount, maxCount int) {
+	counts := make(map[byte]int, len(charset))
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	minCount, maxCount = len(s), 0
+	for _, c := range charset {
+		minCount = min(minCount, counts[c])
+		maxCount = max(maxCount, counts[c])
+	}
+	return minCount, maxCount
+}
+
+func TestStringDistribution(t *testing.T) {
+	t.Parallel()
+	const sampleSize = 620000
+	expected := float64(sampleSize) / float64(len(fastrand.CharsAlphabetDigits))
+	tolerance := 0.05 * expected
+
+	t.Run("Fast", func(t *testing.T) {
+		s := fastrand.String(sampleSize, fastrand.CharsAlphabetDigits)
+		minCount, maxCount := charDistribution(s, fastrand.CharsAlphabetDigits)
+		assert.InDelta(t, expected, minCount, tolerance)
+		assert.InDelta(t, expected, maxCount, tolerance)
+	})
+
+	t.Run("Secure", func(t *testing.T) {
+		s, err := fastrand.SecureString(sampleSize, fastrand.CharsAlphabetDigits)
+		require.NoError(t, err)
+		minCount, maxCount := charDistribution(s, fastrand.CharsAlphabetDigits)
+		assert.InDelta(t, expected, minCount, tolerance)
+		assert.InDelta(t, expected, maxCount, tolerance)
+	})
+
+	t.Run("NaiveModuloIsBiased", func(t *testing.T) {
+		charset := fastrand.CharsAlphabetDigits
+		raw := fastrand.Bytes(sampleSize)
+		b := make([]byte, sampleSize)
+		for i, v := range raw {
+			b[i] = charset[int(v)%len(charset)]
+		}
+		minCount, maxCount := charDistribution(string(b), charset)
+		// 256 % 62 = 8, so the first 8 characters are drawn 5/256 of the
+		// time and the rest 4/256: a 25% skew the checks above would catch.
+		assert.Greater(t, float64(maxCount)/float64(minCount), 1.15)
+	})
+}
+
+func biasShare(b []byte, bias []byte) float64 {
+	count := 0
+	for _, v := range b {
+		if bytes.IndexByte(bias, v) != -1 {
+			count++
+		}
+	}
+	return float64(count) / float64(len(b))
+}
+
+func TestBytesBiased(t *testing.T) {
+	t.Parallel()
+	const sampleSize = 200000
+	uniformShare := float64(len(fastrand.BoundaryBytes)) / 256
+
+	for _, pct := range []int{0, 25, 50, 100} {
+		t.Run(fmt.Sprintf("Pct%d", pct), func(t *testing.T) {
+			b := fastrand.BytesBiased(sampleSize, fastrand.BoundaryBytes, pct)
+			require.Len(t, b, sampleSize)
+			p := float64(pct) / 100
+			expected := p + (1-p)*uniformShare
+			assert.InDelta(t, expected, biasShare(b, fastrand.BoundaryBytes), 0.01)
+		})
+	}
+
+	t.Run("Clamped", func(t *testing.T) {
+		assert.InDelta(t, 1.0, biasShare(fastrand.BytesBiased(1000, []byte{0x41}, 500), []byte{0x41}), 0.0001)
+		assert.InDelta(t, 1.0/256, biasShare(fastrand.BytesBiased(sampleSize, []byte{0x41}, -5), []byte{0x41}), 0.002)
+	})
+
+	t.Run("EmptyBias", func(t *testing.T) {
+		assert.Len(t, fastrand.BytesBiased(32, nil, 100), 32)
+	})
+
+	t.Run("Keyword", func(t *testing.T) {
+		b := fastrand.Randomizer([]byte("{RAND;64k;BYTESBIAS;50}"))
+		require.Len(t, b, 64*1024)
+		assert.InDelta(t, 0.5+0.5*uniformShare, biasShare(b, fastrand.BoundaryBytes), 0.02)
+	})
+}
+
+func TestWeightedChoice(t *testing.T) {
+	t.Parallel()
+	items := []string{"a", "b", "c", "never"}
+	weights := []float64{1, 2, 7, 0}
+	counts := make(map[string]int)
+	const samples = 100000
+	for i := 0; i < samples; i++ {
+		counts[fastrand.WeightedChoice(items, weights)]++
+	}
+	assert.InDelta(t, 0.1, float64(counts["a"])/samples, 0.01)
+	assert.InDelta(t, 0.2, float64(counts["b"])/samples, 0.01)
+	assert.InDelta(t, 0.7, float64(counts["c"])/samples, 0.01)
+	assert.Zero(t, counts["never"])
+
+	assert.PanicsWithValue(t, "fastrand: cannot choose from an empty slice", func() {
+		fastrand.WeightedChoice([]int{}, nil)
+	})
+	assert.PanicsWithValue(t, "fastrand: items and weights must have the same length", func() {
+		fastrand.WeightedChoice([]int{1, 2}, []float64{1})
+	})
+	assert.PanicsWithValue(t, "fastrand: weights must contain a positive value", func() {
+		fastrand.WeightedChoice([]int{1}, []float64{0})
+	})
+}
@@ -3,11 +3,16 @@ package fastrand
 import (
 	"bytes"
 	_ "embed"
+	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"html"
-	"math/rand"
+	"io"
+	"net"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/valyala/bytebufferpool"
 )
@@ -18,31 +23,105 @@ const (
 	RandomizerEncodingNone RandomizerEncoding = 0
 	RandomizerEncodingURL  RandomizerEncoding = 1 << iota
 	RandomizerEncodingHTML
+	// RandomizerEncodingCSV is an output-only encoding. Unlike URL and HTML it
+	// leaves the template text alone and instead quotes each generated value
+	// as an RFC 4180 field when it contains a comma, quote or line break.
+	RandomizerEncodingCSV
 )
 
 type CustomKeywordGenerator func(length int) []byte
 
+// keywordGenerator draws from the Source of the engine expanding the tag.
+type keywordGenerator func(r Source, length int) []byte
+
+const (
+	uniqueAttemptsFactor = 10
+	defaultBiasPercent   = 25
+	fallbackMailProvider = "example.com"
+	maxEmailDomainDepth  = 5
+)
+
 var (
 	defaultEngine     *FastEngine
 	SafeMailProviders []string
+	MimeTypes         []string
+	Locales           []string
+	Emoji             []string
+	SQLiPayloads      []string
+	XSSPayloads       []string
+	HeaderNames       []string
+	languageLocales   []string
+	regionLocales     []string
+	defaultMimeGroups map[string][]string
+	countryBoxes      map[string]geoBox
+	colorPalettes     map[string][]string
 	allKeywords       = []string{
 		"ABL", "ABU", "ABR", "DIGIT", "HEX", "SPACE", "UUID",
-		"NULL", "IPV4", "IPV6", "BYTES", "EMAIL",
+		"NULL", "IPV4", "IPV6", "BYTES", "EMAIL", "MIME",
+		"SHA256", "SHA1", "MD5", "SEMVER", "SEMVERRANGE",
+		"FILENAME", "PATH", "PID", "CYCLIC",
+		"UTF8", "BADUTF8", "HEXDUMP",
+		"RFC3339", "BYTESBIAS", "LOCALE", "UNIQUEENUM", "IDENT", "SPONGE", "QUERY", "EMOJI", "JSONVAL", "RULE", "MAC", "FLAG", "CRLFINJECT", "STATUS", "SQLI", "XSS", "OID", "BASEN", "UNICODE", "BRACKETS", "ISBN", "SHORTID", "HEADERS", "ONCE", "GEO", "HASHPICK", "SPLIT", "CRON", "PROTOWIRE", "METHOD", "COLOR", "MAGIC", "CSVROWS", "STEP", "DNS", "REGEX", "JSONPOINTER", "BASE58", "QUOTED", "YAMLVAL", "LATENCY", "MARKDOWN", "REMEMBER", "RECALL", "XML",
 	}
 )
 
 //go:embed mail_providers.txt
 var mailProviders string
 
+//go:embed mime_types.txt
+var mimeTypes string
+
+//go:embed locales.txt
+var locales string
+
+//go:embed emoji.txt
+var emoji string
+
+//go:embed sqli_payloads.txt
+var sqliPayloads string
+
+//go:embed xss_payloads.txt
+var xssPayloads string
+
+//go:embed header_names.txt
+var headerNames string
+
+//go:embed country_bounds.txt
+var countryBounds string
+
+//go:embed palettes.txt
+var palettes string
+
 func init() {
-	lines := strings.Split(mailProviders, "\n")
-	for _, line := range lines {
+	SafeMailProviders = splitLines(mailProviders)
+	MimeTypes = splitLines(mimeTypes)
+	defaultMimeGroups = groupMimeTypes(MimeTypes)
+	Locales = splitLines(locales)
+	Emoji = splitLines(emoji)
+	SQLiPayloads = splitLines(sqliPayloads)
+	XSSPayloads = splitLines(xssPayloads)
+	HeaderNames = splitLines(headerNames)
+	countryBoxes = parseCountryBounds(countryBounds)
+	colorPalettes = parsePalettes(palettes)
+	for _, locale := range Locales {
+		if strings.Contains(locale, "-") {
+			regionLocales = append(regionLocales, locale)
+		} else {
+			languageLocales = append(languageLocales, locale)
+		}
+	}
+	defaultEngine = NewEngine()
+}
+
+func splitLines(s string) []string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
 		trimmed := strings.TrimSpace(line)
 		if trimmed != "" {
-			SafeMailProviders = append(SafeMailProviders, trimmed)
+			out = append(out, trimmed)
 		}
 	}
-	defaultEngine = NewEngine()
+	return out
 }
 
 func RandomizerString(payload string) string {
@@ -53,54 +132,382 @@ func Randomizer(payload []byte) []byte {
 	return defaultEngine.Randomizer(payload)
 }
 
+func RandomizerTo(w io.Writer, payload []byte) (int64, error) {
+	return defaultEngine.RandomizerTo(w, payload)
+}
+
+func GenerateUnique(template []byte, n int) ([]string, error) {
+	return defaultEngine.GenerateUnique(template, n)
+}
+
+// GenerateUnique expands template until n distinct results are collected. It
+// gives up after about uniqueAttemptsFactor*n attempts, which only happens
+// when the template cannot produce enough distinct values.
+func (e *FastEngine) GenerateUnique(template []byte, n int) ([]string, error) {
+	if n <= 0 {
+		return []string{}, nil
+	}
+
+	results := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	maxAttempts := uniqueAttemptsFactor*n + uniqueAttemptsFactor
+	for attempt := 0; attempt < maxAttempts && len(results) < n; attempt++ {
+		value := string(e.Randomizer(template))
+		if _, dup := seen[value]; dup {
+			continue
+		}
+		seen[value] = struct{}{}
+		results = append(results, value)
+	}
+
+	if len(results) < n {
+		return results, fmt.Errorf("fastrand: only %d of %d unique values generated after %d attempts", len(results), n, maxAttempts)
+	}
+	return results, nil
+}
+
+func ExpandAll(template []byte, n int) []string {
+	return defaultEngine.ExpandAll(template, n)
+}
+
+// ExpandAll returns n independent expansions of template, reusing one buffer
+// for all of them.
+func (e *FastEngine) ExpandAll(template []byte, n int) []string {
+	if n <= 0 {
+		return []string{}
+	}
+
+	results := make([]string, n)
+	if e.passthrough(template) {
+		literal := string(template)
+		for i := range results {
+			results[i] = literal
+		}
+		return results
+	}
+
+	buffer := bytebufferpool.Get()
+	defer bytebufferpool.Put(buffer)
+
+	for i := range results {
+		buffer.Reset()
+		e.reserve(buffer, template)
+		e.randomize(template, buffer, e.outputEncoding)
+		results[i] = buffer.String()
+	}
+	return results
+}
+
+func RandomizerDocs(template []byte) [][]byte {
+	return defaultEngine.RandomizerDocs(template)
+}
+
+// RandomizerDocs splits template at top-level {RAND;;SPLIT} markers and
+// expands each piece as a separate Randomizer call, so every document gets
+// its own ONCE and UNIQUEENUM scope as well as the global prefix and suffix.
+// Markers at the edges or next to each other produce empty documents unless
+// the engine was built with WithDropEmptyDocs.
+func (e *FastEngine) RandomizerDocs(template []byte) [][]byte {
+	template = e.decodeInput(template)
+
+	var docs [][]byte
+	start, cursor := 0, 0
+	for {
+		startIndex := bytes.Index(template[cursor:], startTag)
+		if startIndex == -1 {
+			break
+		}
+		startIndex += cursor
+		endIndex := findTagEnd(template[startIndex:])
+		if endIndex == -1 {
+			break
+		}
+		cursor = startIndex + endIndex + 1
+		if isSplitTag(template[startIndex : startIndex+endIndex]) {
+			docs = e.appendDoc(docs, template[start:startIndex])
+			start = cursor
+		}
+	}
+	return e.appendDoc(docs, template[start:])
+}
+
+func (e *FastEngine) appendDoc(docs [][]byte, piece []byte) [][]byte {
+	if len(piece) == 0 && e.dropEmptyDocs {
+		return docs
+	}
+	return append(docs, e.Randomizer(piece))
+}
+
+// isSplitTag reports whether tag is a SPLIT document separator.
+func isSplitTag(tag []byte) bool {
+	if !isWellFormedTag(tag) {
+		return false
+	}
+	body := tag[len(startTag):]
+	if bytes.HasPrefix(body, startTagOpt) {
+		body = body[len(startTagOpt):]
+	}
+	return bytes.EqualFold(body, []byte(";;SPLIT")) || bytes.EqualFold(body, []byte(";SPLIT"))
+}
+
+func CountTags(template []byte) int {
+	return defaultEngine.CountTags(template)
+}
+
+// CountTags returns the number of well-formed top-level tags in template
+// without expanding them. Encoded tags are counted when the engine's input
+// encoding would decode them; unterminated tags and look-alikes such as
+// {RANDX} are not counted.
+func (e *FastEngine) CountTags(template []byte) int {
+	return e.countTags(template, -1)
+}
+
+func ContainsTags(payload []byte) bool {
+	return defaultEngine.ContainsTags(payload)
+}
+
+// ContainsTags reports whether payload holds a tag Randomizer would expand,
+// so callers can skip tag-free payloads. Encoded tags count only when the
+// engine's input encoding decodes them; escaped look-alikes such as
+// %257BRAND%257D, unterminated tags and {RANDX} do not count.
+func (e *FastEngine) ContainsTags(payload []byte) bool {
+	if bytes.IndexByte(payload, '{') == -1 && !e.hasEncodedInput(payload) {
+		return false
+	}
+	return e.countTags(payload, 1) > 0
+}
+
+// countTags counts the top-level tags in template, up to limit.
+func (e *FastEngine) countTags(template []byte, limit int) int {
+	template = e.decodeInput(template)
+
+	count := 0
+	cursor := 0
+	for count != limit {
+		startIndex := bytes.Index(template[cursor:], startTag)
+		if startIndex == -1 {
+			return count
+		}
+		startIndex += cursor
+		endIndex := findTagEnd(template[startIndex:])
+		if endIndex == -1 {
+			return count
+		}
+		if isWellFormedTag(template[startIndex : startIndex+endIndex]) {
+			count++
+		}
+		cursor = startIndex + endIndex + 1
+	}
+	return count
+}
+
 func (e *FastEngine) RandomizerString(payload string) string {
 	return string(e.Randomizer([]byte(payload)))
 }
 
 func (e *FastEngine) Randomizer(payload []byte) []byte {
-	if !bytes.ContainsAny(payload, "{%&") && e.outputEncoding == RandomizerEncodingNone {
+	if e.passthrough(payload) {
 		return payload
 	}
 
-	if e.inputEncoding != RandomizerEncodingNone && bytes.ContainsAny(payload, "%&") {
-		payload = normalize(payload, e.inputEncoding)
+	buffer := bytebufferpool.Get()
+	defer bytebufferpool.Put(buffer)
+
+	e.reserve(buffer, payload)
+	e.randomize(payload, buffer, e.outputEncoding)
+
+	result := append([]byte(nil), buffer.Bytes()...)
+	return result
+}
+
+// RandomizeIndexed expands template with a source seeded from the engine's
+// WithSeed seed, or 0 without one, combined with index. The same seed and
+// index always give the same output, different indices give independent
+// ones, and the engine's own sequence is left untouched. REMEMBER pools are
+// shared with regular calls.
+func (e *FastEngine) RandomizeIndexed(template []byte, index uint64) []byte {
+	var seed uint64
+	if src, ok := e.src.(*seededSource); ok {
+		seed = src.seed
+	}
+	return e.withSource(newIndexedSource(seed, index)).Randomizer(template)
+}
+
+func (e *FastEngine) RandomizerTo(w io.Writer, payload []byte) (int64, error) {
+	if e.passthrough(payload) {
+		n, err := w.Write(payload)
+		return int64(n), err
 	}
 
 	buffer := bytebufferpool.Get()
 	defer bytebufferpool.Put(buffer)
 
+	e.reserve(buffer, payload)
+	e.randomize(payload, buffer, e.outputEncoding)
+
+	return buffer.WriteTo(w)
+}
+
+// reserve grows buffer up front to fit the expansion of payload.
+func (e *FastEngine) reserve(buffer *bytebufferpool.ByteBuffer, payload []byte) {
+	hint := e.bufferHint
+	if hint == 0 {
+		hint = e.lengthHint(payload)
+	}
+	if need := len(e.globalPrefix) + len(payload) + hint + len(e.globalSuffix); cap(buffer.B) < need {
+		buffer.B = append(make([]byte, 0, need), buffer.B...)
+	}
+}
+
+// lengthHint sums the lengths the tags of payload ask for.
+func (e *FastEngine) lengthHint(payload []byte) int {
+	hint, cursor := 0, 0
+	for hint < e.maxByteLength {
+		startIndex := bytes.Index(payload[cursor:], startTag)
+		if startIndex == -1 {
+			break
+		}
+		startIndex += cursor
+		endIndex := findTagEnd(payload[startIndex:])
+		if endIndex == -1 {
+			break
+		}
+		hint += e.tagLengthHint(payload[startIndex : startIndex+endIndex])
+		cursor = startIndex + endIndex + 1
+	}
+	return min(hint, e.maxByteLength)
+}
+
+// tagLengthHint returns the longest length tag can ask for.
+func (e *FastEngine) tagLengthHint(tag []byte) int {
+	tag = tag[len(startTag):]
+	if bytes.HasPrefix(tag, startTagOpt) {
+		tag = tag[len(startTagOpt):]
+	}
+	if len(tag) == 0 {
+		return e.defaultLength
+	}
+	lenPart, _, _ := bytes.Cut(tag[1:], []byte{sepTag})
+
+	bounds := lengthBounds{e.minLength, e.maxLength}
+	if l, ok := parseLengthFast(lenPart); ok && bounds.contain(l) {
+		return l
+	}
+	if l, ok := parseSizeSuffix(lenPart); ok && l <= e.maxByteLength {
+		return l
+	}
+	if e.rangesEnabled {
+		if _, maxX, ok, _ := e.parseRange(lenPart, bounds); ok {
+			return maxX
+		}
+	}
+	return e.defaultLength
+}
+
+// passthrough reports whether payload would be returned unchanged.
+func (e *FastEngine) passthrough(payload []byte) bool {
+	return bytes.IndexByte(payload, '{') == -1 && !e.hasEncodedInput(payload) &&
+		e.outputEncoding == RandomizerEncodingNone && e.globalPrefix == "" && e.globalSuffix == "" && !e.collapseWhitespace
+}
+
+// hasEncodedInput reports whether payload may hold encoded tags.
+func (e *FastEngine) hasEncodedInput(payload []byte) bool {
+	return e.inputEncoding&RandomizerEncodingURL != 0 && bytes.IndexByte(payload, '%') != -1 ||
+		e.inputEncoding&RandomizerEncodingHTML != 0 && bytes.IndexByte(payload, '&') != -1
+}
+
+// decodeInput turns tags in the engine's input encodings into plain tags.
+func (e *FastEngine) decodeInput(payload []byte) []byte {
+	if !e.hasEncodedInput(payload) {
+		return payload
+	}
+	return normalize(payload, e.inputEncoding)
+}
+
+// randomize expands payload between the global prefix and suffix.
+func (e *FastEngine) randomize(payload []byte, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding) {
+	e.randomizeState(payload, buffer, encoding, &callState{})
+}
+
+func (e *FastEngine) randomizeState(payload []byte, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState) {
+	_, _ = buffer.WriteString(e.globalPrefix)
+	e.randomizeWith(payload, buffer, encoding, state)
+	_, _ = buffer.WriteString(e.globalSuffix)
+}
+
+func (e *FastEngine) randomizeWith(payload []byte, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState) {
+	payload = e.decodeInput(payload)
+
+	e.expand(payload, buffer, encoding, state)
+}
+
+// expand replaces the tags of an already normalized payload.
+func (e *FastEngine) expand(payload []byte, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState) {
+	e.expandTags(payload, buffer, encoding, state, e.collapseWhitespace)
+}
+
+// expandTags is expand with whitespace collapsing controlled by collapse.
+func (e *FastEngine) expandTags(payload []byte, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState, collapse bool) {
+	writeLiteral := writeEncoded
+	if collapse {
+		writeLiteral = func(buffer *bytebufferpool.ByteBuffer, data []byte, encoding RandomizerEncoding) {
+			writeCollapsed(buffer, data, encoding, state)
+		}
+	}
+
 	cursor := 0
 	for {
 		startIndex := bytes.Index(payload[cursor:], startTag)
 		if startIndex == -1 {
-			e.writeEncoded(buffer, payload[cursor:])
+			writeLiteral(buffer, payload[cursor:], encoding)
 			break
 		}
 		startIndex += cursor
-		e.writeEncoded(buffer, payload[cursor:startIndex])
+		writeLiteral(buffer, payload[cursor:startIndex], encoding)
 
 		cursor = startIndex
-		endIndex := bytes.IndexByte(payload[cursor:], endTag)
+		endIndex := findTagEnd(payload[cursor:])
 		if endIndex == -1 {
-			e.writeEncoded(buffer, payload[cursor:])
+			writeLiteral(buffer, payload[cursor:], encoding)
 			break
 		}
 		endIndex += cursor
 		tag := payload[cursor:endIndex]
 		cursor = endIndex + 1
 
-		e.parseAndReplaceFast(tag, buffer)
+		mark := len(buffer.B)
+		e.parseAndReplaceFast(tag, buffer, encoding, state)
+		if collapse {
+			state.collapseSpace(buffer, mark)
+		}
+		if encoding == RandomizerEncodingCSV {
+			quoteCSVField(buffer, mark)
+		}
 	}
+}
 
-	result := append([]byte(nil), buffer.Bytes()...)
-	return result
+// writeCollapsed is writeEncoded with whitespace collapsed first.
+func writeCollapsed(buffer *bytebufferpool.ByteBuffer, data []byte, encoding RandomizerEncoding, state *callState) {
+	mark := len(buffer.B)
+	buffer.B = append(buffer.B, data...)
+	state.collapseSpace(buffer, mark)
+	if encoding == RandomizerEncodingURL || encoding == RandomizerEncodingHTML {
+		collapsed := append([]byte(nil), buffer.B[mark:]...)
+		buffer.B = buffer.B[:mark]
+		writeEncoded(buffer, collapsed, encoding)
+	}
+}
+
+// appendInt writes the decimal form of n into buffer.
+func appendInt(buffer *bytebufferpool.ByteBuffer, n int64) {
+	buffer.B = strconv.AppendInt(buffer.B, n, 10)
 }
 
-func (e *FastEngine) writeEncoded(buffer *bytebufferpool.ByteBuffer, data []byte) {
+func writeEncoded(buffer *bytebufferpool.ByteBuffer, data []byte, encoding RandomizerEncoding) {
 	if len(data) == 0 {
 		return
 	}
-	switch e.outputEncoding {
+	switch encoding {
 	case RandomizerEncodingURL:
 		_, _ = buffer.WriteString(url.QueryEscape(string(data)))
 	case RandomizerEncodingHTML:
@@ -110,26 +517,40 @@ func (e *FastEngine) writeEncoded(buffer *bytebufferpool.ByteBuffer, data []byte
 	}
 }
 
-func (e *FastEngine) parseAndReplaceFast(tag []byte, buffer *bytebufferpool.ByteBuffer) {
+// quoteCSVField quotes buffer.B[mark:] in place if CSV needs it.
+func quoteCSVField(buffer *bytebufferpool.ByteBuffer, mark int) {
+	field := buffer.B[mark:]
+	if !bytes.ContainsAny(field, ",\"\r\n") {
+		return
+	}
+	quoted := make([]byte, 0, len(field)+2+bytes.Count(field, []byte{'"'}))
+	quoted = append(quoted, '"')
+	for _, c := range field {
+		if c == '"' {
+			quoted = append(quoted, '"')
+		}
+		quoted = append(quoted, c)
+	}
+	quoted = append(quoted, '"')
+	buffer.B = append(buffer.B[:mark], quoted...)
+}
+
+// parseAndReplaceFast expands a single tag (without its closing brace).
+func (e *FastEngine) parseAndReplaceFast(tag []byte, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState) {
+	original := tag
 	tag = tag[len(startTag):]
 	if bytes.HasPrefix(tag, startTagOpt) {
 		tag = tag[len(startTagOpt):]
 	}
 
-	if len(tag) == 0 {
-		_, _ = buffer.WriteString(String(e.defaultLength, CharsAll))
+	if !isWellFormedTag(original) {
+		writeEncoded(buffer, original, encoding)
+		writeEncoded(buffer, []byte{endTag}, encoding)
 		return
 	}
 
-	if tag[0] != sepTag {
-		tempBuf := bytebufferpool.Get()
-		defer bytebufferpool.Put(tempBuf)
-		_, _ = tempBuf.Write(startTag)
-		if bytes.HasPrefix(tag, startTagOpt) {
-			_, _ = tempBuf.Write(startTagOpt)
-		}
-		_, _ = tempBuf.Write(tag)
-		e.writeEncoded(buffer, tempBuf.Bytes())
+	if len(tag) == 0 {
+		buffer.B = appendRandString(e.src, buffer.B, lengthBounds{e.minLength, e.maxLength}.clamp(e.defaultLength), e.defaultCharset)
 		return
 	}
 	tag = tag[1:]
@@ -145,132 +566,390 @@ func (e *FastEngine) parseAndReplaceFast(tag []byte, buffer *bytebufferpool.Byte
 		typeKeyword = tag[sepIndex+1:]
 	}
 
+	bounds := e.lengthBoundsFor(typeKeyword)
 	var lengthParsed bool
-	if e.lengthChoicesEnabled && bytes.Contains(lenPart, []byte(",")) {
-		var validLengths []int
-		start := 0
-		for {
-			idx := bytes.IndexByte(lenPart[start:], ',')
-			var part []byte
-			if idx == -1 {
-				part = lenPart[start:]
-				if l, ok := parseLengthFast(part); ok && l >= e.minLength && l <= e.maxLength {
-					validLengths = append(validLengths, l)
-				}
-				break
-			}
-			part = lenPart[start : start+idx]
-			if l, ok := parseLengthFast(part); ok && l >= e.minLength && l <= e.maxLength {
-				validLengths = append(validLengths, l)
-			}
-			start += idx + 1
-		}
-
-		if len(validLengths) > 0 {
-			length = validLengths[rand.Intn(len(validLengths))]
+	if e.lengthChoicesEnabled && bytes.IndexByte(lenPart, ',') != -1 {
+		part, ok := pickChoice(e.src, lenPart, func(part []byte) bool {
+			l, ok := parseLengthFast(part)
+			return ok && bounds.contain(l)
+		})
+		if ok {
+			length, _ = parseLengthFast(part)
 			lengthParsed = true
 		}
 	}
 
 	if !lengthParsed && e.rangesEnabled && bytes.Contains(lenPart, []byte("-")) {
-		rangeSepIndex := bytes.IndexByte(lenPart, '-')
-		if rangeSepIndex != -1 {
-			minPart := lenPart[:rangeSepIndex]
-			maxPart := lenPart[rangeSepIndex+1:]
-			if minX, ok1 := parseLengthFast(minPart); ok1 && minX >= e.minLength {
-				if maxX, ok2 := parseLengthFast(maxPart); ok2 && minX <= maxX && maxX <= e.maxLength {
-					length = rand.Intn(maxX-minX+1) + minX
-					lengthParsed = true
-				}
-			}
+		minX, maxX, ok, inverted := e.parseRange(lenPart, bounds)
+		if inverted && e.strictRanges {
+			writeEncoded(buffer, original, encoding)
+			writeEncoded(buffer, []byte{endTag}, encoding)
+			return
+		}
+		if ok {
+			length = e.src.Intn(maxX-minX+1) + minX
+			lengthParsed = true
 		}
 	}
 
+	var sizedLength bool
 	if !lengthParsed {
-		if l, ok := parseLengthFast(lenPart); ok && l >= e.minLength && l <= e.maxLength {
+		if l, ok := parseLengthFast(lenPart); ok && bounds.contain(l) {
 			length = l
+		} else if l, ok := parseSizeSuffix(lenPart); ok && l <= e.maxByteLength {
+			length = l
+			sizedLength = true
 		} else if typeKeyword == nil {
 			typeKeyword = lenPart
 		}
 	}
 
-	if length < e.minLength {
-		length = e.minLength
+	var arg []byte
+	if argIndex := bytes.IndexByte(typeKeyword, sepTag); argIndex != -1 {
+		arg = typeKeyword[argIndex+1:]
+		typeKeyword = typeKeyword[:argIndex]
 	}
 
-	if e.keywordChoicesEnabled && bytes.Contains(typeKeyword, []byte(",")) {
-		var validChoices [][]byte
-		start := 0
-		for {
-			idx := bytes.IndexByte(typeKeyword[start:], ',')
-			var choice []byte
-			if idx == -1 {
-				choice = typeKeyword[start:]
-				upcasedChoice := strings.ToUpper(string(choice))
-				_, isCustom := e.customKeywords[upcasedChoice]
-				isEnabled := e.enabledKeywords[upcasedChoice]
-				if isCustom || isEnabled {
-					validChoices = append(validChoices, choice)
-				}
-				break
-			}
-			choice = typeKeyword[start : start+idx]
-			upcasedChoice := strings.ToUpper(string(choice))
-			_, isCustom := e.customKeywords[upcasedChoice]
-			isEnabled := e.enabledKeywords[upcasedChoice]
-			if isCustom || isEnabled {
-				validChoices = append(validChoices, choice)
-			}
-			start += idx + 1
-		}
-		if len(validChoices) > 0 {
-			typeKeyword = validChoices[rand.Intn(len(validChoices))]
+	if e.keywordChoicesEnabled && bytes.IndexByte(typeKeyword, ',') != -1 {
+		if choice, ok := pickChoice(e.src, typeKeyword, e.isKnownKeyword); ok {
+			typeKeyword = choice
 		}
 	}
 
 	upcasedKeyword := strings.ToUpper(string(typeKeyword))
-	if customGen, exists := e.customKeywords[upcasedKeyword]; exists {
-		_, _ = buffer.Write(customGen(length))
+	if resolved := e.resolveAlias(upcasedKeyword); resolved != upcasedKeyword {
+		upcasedKeyword = resolved
+		typeKeyword = []byte(resolved)
+	}
+
+	if sizedLength && !isSizedKeyword(upcasedKeyword) {
+		length, sizedLength = e.defaultLength, false
+	}
+
+	// Lengths other than byte sizes always end up within the bounds, even a
+	// default length above WithMaxLength, so repeating keywords such as SPACE
+	// and NULL cannot outgrow them.
+	if kb, ok := e.keywordLengthBounds[upcasedKeyword]; ok {
+		length = kb.clamp(length)
+	} else if !sizedLength {
+		length = bounds.clamp(length)
+	}
+
+	if len(typeKeyword) == 0 {
+		buffer.B = appendRandString(e.src, buffer.B, length, e.defaultCharset)
 		return
 	}
 
-	if enabled, exists := e.enabledKeywords[upcasedKeyword]; !exists || !enabled {
-		_, _ = buffer.WriteString(String(length, e.getCharset(kwABR, CharsAll)))
+	if !e.preserveOnError {
+		e.generateKeyword(typeKeyword, upcasedKeyword, arg, length, buffer, encoding, state)
 		return
 	}
 
+	mark := len(buffer.B)
+	if !e.tryGenerateKeyword(typeKeyword, upcasedKeyword, arg, length, buffer, encoding, state) {
+		buffer.B = buffer.B[:mark]
+		writeEncoded(buffer, original, encoding)
+		writeEncoded(buffer, []byte{endTag}, encoding)
+	}
+}
+
+// tryGenerateKeyword is generateKeyword with panics recovered.
+func (e *FastEngine) tryGenerateKeyword(typeKeyword []byte, upcasedKeyword string, arg []byte, length int, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return e.generateKeyword(typeKeyword, upcasedKeyword, arg, length, buffer, encoding, state)
+}
+
+// generateKeyword writes the value of a keyword and reports whether it worked.
+func (e *FastEngine) generateKeyword(typeKeyword []byte, upcasedKeyword string, arg []byte, length int, buffer *bytebufferpool.ByteBuffer, encoding RandomizerEncoding, state *callState) bool {
+	r := e.src
+	if customGen, exists := e.customKeywords[upcasedKeyword]; exists {
+		value := customGen(r, length)
+		_, _ = buffer.Write(value)
+		return value != nil
+	}
+
+	if charset := e.customCharsets[upcasedKeyword]; len(charset) > 0 {
+		buffer.B = appendRandString(r, buffer.B, length, charset)
+		return true
+	}
+
+	if enabled, exists := e.enabledKeywords[upcasedKeyword]; !exists || !enabled {
+		buffer.B = appendRandString(r, buffer.B, length, e.getCharset(kwABR, e.defaultCharset))
+		return true
+	}
+
 	switch {
 	case bytes.EqualFold(typeKeyword, kwABL):
-		_, _ = buffer.WriteString(String(length, e.getCharset(kwABL, CharsAlphabetLower)))
+		buffer.B = appendRandString(r, buffer.B, length, e.getCharset(kwABL, CharsAlphabetLower))
 	case bytes.EqualFold(typeKeyword, kwABU):
-		_, _ = buffer.WriteString(String(length, e.getCharset(kwABU, CharsAlphabetUpper)))
+		buffer.B = appendRandString(r, buffer.B, length, e.getCharset(kwABU, CharsAlphabetUpper))
 	case bytes.EqualFold(typeKeyword, kwABR):
-		_, _ = buffer.WriteString(String(length, e.getCharset(kwABR, CharsAlphabet)))
+		buffer.B = appendRandString(r, buffer.B, length, e.getCharset(kwABR, CharsAlphabet))
 	case bytes.EqualFold(typeKeyword, kwDIGIT):
-		_, _ = buffer.WriteString(String(length, e.getCharset(kwDIGIT, CharsDigits)))
+		buffer.B = appendRandString(r, buffer.B, length, e.getCharset(kwDIGIT, CharsDigits))
 	case bytes.EqualFold(typeKeyword, kwNULL):
 		nullCharset := e.getCharset(kwNULL, CharsNull)
 		for i := 0; i < length; i++ {
-			_ = buffer.WriteByte(Choice(nullCharset))
+			_ = buffer.WriteByte(pick(r, nullCharset))
 		}
 	case bytes.EqualFold(typeKeyword, kwSPACE):
 		for i := 0; i < length; i++ {
 			_ = buffer.WriteByte(' ')
 		}
 	case bytes.EqualFold(typeKeyword, kwUUID):
-		_, _ = buffer.Write(generateUUID())
+		_, _ = buffer.Write(generateUUID(r, arg))
 	case bytes.EqualFold(typeKeyword, kwBYTES):
-		_, _ = buffer.Write(Bytes(length))
+		_, _ = buffer.Write(randBytes(r, length))
 	case bytes.EqualFold(typeKeyword, kwIPV4):
-		_, _ = buffer.WriteString(IPv4().String())
+		_, _ = buffer.Write(formatIPv4(randBytes(r, net.IPv4len), arg))
 	case bytes.EqualFold(typeKeyword, kwIPV6):
-		_, _ = buffer.WriteString(IPv6().String())
+		_, _ = buffer.WriteString(net.IP(randBytes(r, net.IPv6len)).String())
 	case bytes.EqualFold(typeKeyword, kwEMAIL):
-		_, _ = buffer.Write(e.generateRandomEmail(length))
+		_, _ = buffer.Write(e.generateRandomEmail(length, arg))
 	case bytes.EqualFold(typeKeyword, kwHEX):
-		_, _ = buffer.Write(generateRandomHex(length, e.defaultLength))
+		_, _ = buffer.Write(generateRandomHex(r, length, e.defaultLength))
+	case bytes.EqualFold(typeKeyword, kwSHA256), bytes.EqualFold(typeKeyword, kwSHA1), bytes.EqualFold(typeKeyword, kwMD5):
+		input := e.expandArg(arg, state)
+		if len(input) == 0 {
+			input = randBytes(r, length)
+		}
+		_, _ = buffer.Write(generateDigest(typeKeyword, input))
+	case bytes.EqualFold(typeKeyword, kwSEMVER):
+		buffer.B = appendSemver(r, buffer.B, arg)
+	case bytes.EqualFold(typeKeyword, kwSEMVERRANGE):
+		buffer.B = appendSemverRange(r, buffer.B, arg)
+	case bytes.EqualFold(typeKeyword, kwFILENAME):
+		_, _ = buffer.Write(generateFilename(r, length, arg))
+	case bytes.EqualFold(typeKeyword, kwPATH):
+		_, _ = buffer.Write(generatePath(r, length, arg))
+	case bytes.EqualFold(typeKeyword, kwPID):
+		appendInt(buffer, randomPID(r, arg))
+	case bytes.EqualFold(typeKeyword, kwCYCLIC):
+		_, _ = buffer.Write(Cyclic(length))
+	case bytes.EqualFold(typeKeyword, kwUTF8):
+		_, _ = buffer.Write(generateUTF8(r, length))
+	case bytes.EqualFold(typeKeyword, kwBADUTF8):
+		_, _ = buffer.Write(generateBadUTF8(r, length, arg))
+	case bytes.EqualFold(typeKeyword, kwHEXDUMP):
+		_, _ = buffer.WriteString(hex.Dump(randBytes(r, length)))
+	case bytes.EqualFold(typeKeyword, kwRFC3339):
+		_, _ = buffer.Write(e.generateRFC3339())
+	case bytes.EqualFold(typeKeyword, kwBYTESBIAS):
+		pct, err := strconv.Atoi(string(arg))
+		if err != nil {
+			pct = defaultBiasPercent
+		}
+		_, _ = buffer.Write(biasedBytes(r, length, BoundaryBytes, pct))
+	case bytes.EqualFold(typeKeyword, kwLOCALE):
+		_, _ = buffer.WriteString(generateLocale(r, arg))
+	case bytes.EqualFold(typeKeyword, kwUNIQUEENUM):
+		set, values, _ := bytes.Cut(arg, []byte{sepTag})
+		_, _ = buffer.Write(state.takeUnique(r, string(set), values))
+	case bytes.EqualFold(typeKeyword, kwIDENT):
+		_, _ = buffer.Write(generateIdent(r, length))
+	case bytes.EqualFold(typeKeyword, kwSPONGE):
+		input := e.expandArg(arg, state)
+		if len(input) == 0 {
+			input = []byte(randString(r, length, CharsAlphabetLower))
+		}
+		_, _ = buffer.Write(spongeCase(r, input))
+	case bytes.EqualFold(typeKeyword, kwQUERY):
+		_, _ = buffer.WriteString(generateQuery(r, length))
+	case bytes.EqualFold(typeKeyword, kwEMOJI):
+		for i := 0; i < length; i++ {
+			_, _ = buffer.WriteString(pick(r, Emoji))
+		}
+	case bytes.EqualFold(typeKeyword, kwJSONVAL):
+		_, _ = buffer.Write(appendJSONValue(r, nil, strings.ToLower(string(arg))))
+	case bytes.EqualFold(typeKeyword, kwRULE):
+		_, _ = buffer.Write(generateRule(length, arg))
+	case bytes.EqualFold(typeKeyword, kwMAC):
+		_, _ = buffer.WriteString(generateMAC(r, parseOUI(arg)).String())
+	case bytes.EqualFold(typeKeyword, kwFLAG):
+		_, _ = buffer.WriteString(generateFlag(r, arg))
+	case bytes.EqualFold(typeKeyword, kwCRLFINJECT):
+		_, _ = buffer.Write(e.generateCRLFInject(e.expandArg(arg, state), state))
+	case bytes.EqualFold(typeKeyword, kwSTATUS):
+		appendInt(buffer, int64(pick(r, statusCodesFor(arg))))
+	case bytes.EqualFold(typeKeyword, kwSQLI):
+		_, _ = buffer.WriteString(pick(r, e.sqliPayloads))
+	case bytes.EqualFold(typeKeyword, kwXSS):
+		payload := []byte(pick(r, e.xssPayloads))
+		if bytes.EqualFold(arg, []byte("encoded")) {
+			writeEncoded(buffer, payload, encoding)
+		} else {
+			_, _ = buffer.Write(payload)
+		}
+	case bytes.EqualFold(typeKeyword, kwOID):
+		_, _ = buffer.Write(generateOID(r, length))
+	case bytes.EqualFold(typeKeyword, kwBASEN):
+		buffer.B = appendBaseN(r, buffer.B, length, arg)
+	case bytes.EqualFold(typeKeyword, kwUNICODE):
+		_, _ = buffer.Write(generateUnicodeBlock(r, length, unicodeBlockFor(arg)))
+	case bytes.EqualFold(typeKeyword, kwBRACKETS):
+		_, _ = buffer.Write(generateBrackets(r, length, arg))
+	case bytes.EqualFold(typeKeyword, kwISBN):
+		_, _ = buffer.Write(generateISBN(r, arg))
+	case bytes.EqualFold(typeKeyword, kwSHORTID):
+		_, _ = buffer.Write(generateShortID(r, length))
+	case bytes.EqualFold(typeKeyword, kwHEADERS):
+		_, _ = buffer.Write(e.generateHeaders(length, arg, state))
+	case bytes.EqualFold(typeKeyword, kwONCE):
+		_, _ = buffer.Write(e.generateOnce(arg, state))
+	case bytes.EqualFold(typeKeyword, kwGEO):
+		_, _ = buffer.Write(generateGeo(r, arg))
+	case bytes.EqualFold(typeKeyword, kwHASHPICK):
+		key, choices := hashPickArgs(arg)
+		_, _ = buffer.Write(hashPick(e.expandArg(key, state), choices))
+	case bytes.EqualFold(typeKeyword, kwSPLIT):
+		// Only meaningful to RandomizerDocs; elsewhere it expands to nothing.
+	case bytes.EqualFold(typeKeyword, kwCRON):
+		_, _ = buffer.Write(generateCron(r, arg))
+	case bytes.EqualFold(typeKeyword, kwPROTOWIRE):
+		_, _ = buffer.Write(generateProtoWire(r, length))
+	case bytes.EqualFold(typeKeyword, kwMETHOD):
+		_, _ = buffer.WriteString(e.generateMethod(arg))
+	case bytes.EqualFold(typeKeyword, kwCOLOR):
+		buffer.B = e.appendColor(r, buffer.B, arg)
+	case bytes.EqualFold(typeKeyword, kwMAGIC):
+		_, _ = buffer.Write(fileMagic(arg))
+		_, _ = buffer.Write(randBytes(r, length))
+	case bytes.EqualFold(typeKeyword, kwCSVROWS):
+		return e.generateCSVRows(length, arg, buffer, encoding, state)
+	case bytes.EqualFold(typeKeyword, kwSTEP):
+		v, ok := stepValue(r, arg)
+		if !ok {
+			return false
+		}
+		appendInt(buffer, v)
+	case bytes.EqualFold(typeKeyword, kwDNS):
+		buffer.B = appendDNSValue(r, buffer.B, length, arg)
+	case bytes.EqualFold(typeKeyword, kwREGEX):
+		_, _ = buffer.Write(generateRegex(r, length))
+	case bytes.EqualFold(typeKeyword, kwJSONPOINTER):
+		_, _ = buffer.Write(generateJSONPointer(r, length))
+	case bytes.EqualFold(typeKeyword, kwBASE58):
+		buffer.B = appendRandString(r, buffer.B, length, e.getCharset(kwBASE58, CharsBase58))
+	case bytes.EqualFold(typeKeyword, kwQUOTED):
+		buffer.B = appendQuoted(buffer.B, appendRandString(r, nil, length, e.getCharset(kwQUOTED, quotedChars)), arg)
+	case bytes.EqualFold(typeKeyword, kwYAMLVAL):
+		buffer.B = appendYAMLValue(r, buffer.B, strings.ToLower(string(bytes.TrimSpace(arg))))
+	case bytes.EqualFold(typeKeyword, kwLATENCY):
+		var ok bool
+		if buffer.B, ok = appendLatency(r, buffer.B, arg); !ok {
+			return false
+		}
+	case bytes.EqualFold(typeKeyword, kwMARKDOWN):
+		buffer.B = appendMarkdown(r, buffer.B, length, strings.ToLower(string(bytes.TrimSpace(arg))))
+	case bytes.EqualFold(typeKeyword, kwREMEMBER):
+		_, _ = buffer.Write(e.generateRemember(arg, state))
+	case bytes.EqualFold(typeKeyword, kwRECALL):
+		_, _ = buffer.Write(e.recallValue(r, string(arg)))
+	case bytes.EqualFold(typeKeyword, kwXML):
+		_, _ = buffer.Write(generateXML(r, arg))
+	case bytes.EqualFold(typeKeyword, kwMIME):
+		_, _ = buffer.WriteString(e.generateMimeType(arg))
 	default:
-		_, _ = buffer.WriteString(String(length, e.getCharset(kwABR, CharsAll)))
+		buffer.B = appendRandString(r, buffer.B, length, e.getCharset(kwABR, e.defaultCharset))
+	}
+	return true
+}
+
+// parseRange parses a min-max length range within bounds.
+func (e *FastEngine) parseRange(lenPart []byte, bounds lengthBounds) (minX, maxX int, ok, inverted bool) {
+	minPart, maxPart, found := bytes.Cut(lenPart, []byte("-"))
+	if !found {
+		return 0, 0, false, false
+	}
+	minX, ok1 := parseLengthFast(minPart)
+	maxX, ok2 := parseLengthFast(maxPart)
+	if !ok1 || !ok2 {
+		return 0, 0, false, false
+	}
+	if minX > maxX {
+		if e.strictRanges {
+			return 0, 0, false, true
+		}
+		minX, maxX, inverted = maxX, minX, true
+	}
+	return minX, maxX, bounds.contain(minX) && bounds.contain(maxX), inverted
+}
+
+// lengthBounds are the inclusive limits a length must fall within.
+type lengthBounds struct {
+	min, max int
+}
+
+func (b lengthBounds) contain(length int) bool {
+	return length >= b.min && length <= b.max
+}
+
+func (b lengthBounds) clamp(length int) int {
+	return min(max(length, b.min), b.max)
+}
+
+// lengthBoundsFor returns the length bounds for the keyword part of a tag.
+func (e *FastEngine) lengthBoundsFor(typeKeyword []byte) lengthBounds {
+	global := lengthBounds{e.minLength, e.maxLength}
+	if len(e.keywordLengthBounds) == 0 {
+		return global
+	}
+	keyword, _, _ := bytes.Cut(typeKeyword, []byte{sepTag})
+	if e.keywordChoicesEnabled && bytes.IndexByte(keyword, ',') != -1 {
+		return global
+	}
+	if b, ok := e.keywordLengthBounds[e.resolveAlias(strings.ToUpper(string(keyword)))]; ok {
+		return b
+	}
+	return global
+}
+
+// pickChoice picks one of the comma-separated segments valid accepts.
+func pickChoice(r Source, list []byte, valid func([]byte) bool) ([]byte, bool) {
+	count := 0
+	for rest, more := list, true; more; {
+		var segment []byte
+		segment, rest, more = bytes.Cut(rest, []byte{','})
+		if valid(segment) {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil, false
+	}
+
+	n := r.Intn(count)
+	for rest, more := list, true; more; {
+		var segment []byte
+		segment, rest, more = bytes.Cut(rest, []byte{','})
+		if valid(segment) {
+			if n == 0 {
+				return segment, true
+			}
+			n--
+		}
+	}
+	return nil, false
+}
+
+func (e *FastEngine) isKnownKeyword(keyword []byte) bool {
+	upcased := e.resolveAlias(strings.ToUpper(string(keyword)))
+	_, isCustom := e.customKeywords[upcased]
+	return isCustom || len(e.customCharsets[upcased]) > 0 || e.enabledKeywords[upcased]
+}
+
+// resolveAlias follows alias chains to the final keyword.
+func (e *FastEngine) resolveAlias(keyword string) string {
+	for {
+		target, ok := e.aliases[keyword]
+		if !ok {
+			return keyword
+		}
+		keyword = target
 	}
 }
 
@@ -281,24 +960,77 @@ func (e *FastEngine) getCharset(keyword []byte, fallback CharsList) CharsList {
 	return fallback
 }
 
-func (e *FastEngine) generateRandomEmail(userLength int) []byte {
+func (e *FastEngine) providers() []string {
+	if len(e.mailProviders) > 0 || e.noEmbeddedProviders {
+		return e.mailProviders
+	}
+	return SafeMailProviders
+}
+
+// generateRandomEmail builds user@domain within maxEmailLength.
+func (e *FastEngine) generateRandomEmail(userLength int, modifier []byte) []byte {
+	r := e.src
 	if userLength <= 0 {
 		userLength = 8
 	}
-	user := String(userLength, e.getCharset(kwABL, CharsAlphabetLower))
-	provider := "gmail.com"
-	if len(e.mailProviders) > 0 {
-		provider = Choice(e.mailProviders)
+	plus, depth := emailModifiers(modifier)
+	domain := e.appendEmailDomain(r, nil, depth)
+
+	var tagLength int
+	if plus {
+		tagLength = intRange(r, 3, 8)
+	}
+	if e.maxEmailLength > 0 {
+		budget := max(e.maxEmailLength-len(domain)-1, 1)
+		if tagLength > 0 {
+			userLength = max(min(userLength, budget-tagLength-1), 1)
+			tagLength = max(min(tagLength, budget-userLength-1), 0)
+		}
+		userLength = min(userLength, budget)
 	}
 
-	emailLen := len(user) + 1 + len(provider)
-	b := make([]byte, emailLen)
-	copy(b, user)
-	b[len(user)] = '@'
-	copy(b[len(user)+1:], provider)
+	b := make([]byte, 0, userLength+tagLength+2+len(domain))
+	b = append(b, randString(r, userLength, e.getCharset(kwABL, CharsAlphabetLower))...)
+	if tagLength > 0 {
+		b = append(b, '+')
+		b = append(b, randString(r, tagLength, e.getCharset(kwABL, CharsAlphabetLower))...)
+	}
+	b = append(b, '@')
+	b = append(b, domain...)
 	return b
 }
 
+// emailModifiers parses the comma-separated EMAIL modifiers.
+func emailModifiers(modifier []byte) (plus bool, depth int) {
+	for _, m := range bytes.Split(modifier, []byte(",")) {
+		m = bytes.TrimSpace(m)
+		if bytes.EqualFold(m, []byte("plus")) {
+			plus = true
+		} else if n, err := strconv.Atoi(string(m)); err == nil && n >= 1 && n <= maxEmailDomainDepth {
+			depth = n
+		}
+	}
+	return plus, depth
+}
+
+// appendEmailDomain appends the domain of an EMAIL address.
+func (e *FastEngine) appendEmailDomain(r Source, dst []byte, depth int) []byte {
+	if e.emailDomainMode == EmailDomainRandom {
+		dst = appendDomainLabels(r, dst, max(depth, 1))
+		dst = append(dst, '.')
+		return append(dst, pick(r, hostnameTLDs)...)
+	}
+	provider := fallbackMailProvider
+	if providers := e.providers(); len(providers) > 0 {
+		provider = pick(r, providers)
+	}
+	if depth > 1 {
+		dst = appendDomainLabels(r, dst, depth-1)
+		dst = append(dst, '.')
+	}
+	return append(dst, provider...)
+}
+
 var (
 	startTag         = []byte("{RAND")
 	startUrlEncoded  = []byte("%7BRAND")
@@ -322,6 +1054,64 @@ var (
 	kwIPV6           = []byte("IPV6")
 	kwBYTES          = []byte("BYTES")
 	kwEMAIL          = []byte("EMAIL")
+	kwMIME           = []byte("MIME")
+	kwSHA256         = []byte("SHA256")
+	kwSHA1           = []byte("SHA1")
+	kwMD5            = []byte("MD5")
+	kwSEMVER         = []byte("SEMVER")
+	kwSEMVERRANGE    = []byte("SEMVERRANGE")
+	kwFILENAME       = []byte("FILENAME")
+	kwPATH           = []byte("PATH")
+	kwPID            = []byte("PID")
+	kwCYCLIC         = []byte("CYCLIC")
+	kwUTF8           = []byte("UTF8")
+	kwBADUTF8        = []byte("BADUTF8")
+	kwHEXDUMP        = []byte("HEXDUMP")
+	kwRFC3339        = []byte("RFC3339")
+	kwBYTESBIAS      = []byte("BYTESBIAS")
+	kwLOCALE         = []byte("LOCALE")
+	kwUNIQUEENUM     = []byte("UNIQUEENUM")
+	kwIDENT          = []byte("IDENT")
+	kwSPONGE         = []byte("SPONGE")
+	kwQUERY          = []byte("QUERY")
+	kwEMOJI          = []byte("EMOJI")
+	kwJSONVAL        = []byte("JSONVAL")
+	kwRULE           = []byte("RULE")
+	kwMAC            = []byte("MAC")
+	kwFLAG           = []byte("FLAG")
+	kwCRLFINJECT     = []byte("CRLFINJECT")
+	kwSTATUS         = []byte("STATUS")
+	kwSQLI           = []byte("SQLI")
+	kwXSS            = []byte("XSS")
+	kwOID            = []byte("OID")
+	kwBASEN          = []byte("BASEN")
+	kwUNICODE        = []byte("UNICODE")
+	kwBRACKETS       = []byte("BRACKETS")
+	kwISBN           = []byte("ISBN")
+	kwSHORTID        = []byte("SHORTID")
+	kwHEADERS        = []byte("HEADERS")
+	kwONCE           = []byte("ONCE")
+	kwGEO            = []byte("GEO")
+	kwHASHPICK       = []byte("HASHPICK")
+	kwSPLIT          = []byte("SPLIT")
+	kwCRON           = []byte("CRON")
+	kwPROTOWIRE      = []byte("PROTOWIRE")
+	kwMETHOD         = []byte("METHOD")
+	kwCOLOR          = []byte("COLOR")
+	kwMAGIC          = []byte("MAGIC")
+	kwCSVROWS        = []byte("CSVROWS")
+	kwSTEP           = []byte("STEP")
+	kwDNS            = []byte("DNS")
+	kwREGEX          = []byte("REGEX")
+	kwJSONPOINTER    = []byte("JSONPOINTER")
+	kwBASE58         = []byte("BASE58")
+	kwQUOTED         = []byte("QUOTED")
+	kwYAMLVAL        = []byte("YAMLVAL")
+	kwLATENCY        = []byte("LATENCY")
+	kwMARKDOWN       = []byte("MARKDOWN")
+	kwREMEMBER       = []byte("REMEMBER")
+	kwRECALL         = []byte("RECALL")
+	kwXML            = []byte("XML")
 )
 
 func normalize(payload []byte, encodingFlags RandomizerEncoding) []byte {
@@ -341,13 +1131,13 @@ func normalize(payload []byte, encodingFlags RandomizerEncoding) []byte {
 		char := payload[cursor]
 
 		if char == '%' && (encodingFlags&RandomizerEncodingURL != 0) {
-			if hasPrefix(payload, startUrlEncoded, cursor) {
+			if hasEscape(payload, startUrlEncoded[:3], cursor) && hasPrefix(payload, startUrlEncoded[3:], cursor+3) {
 				_, _ = normalizedBuf.Write(startTag)
 				cursor += len(startUrlEncoded)
-			} else if hasPrefix(payload, endTagUrl, cursor) {
+			} else if hasEscape(payload, endTagUrl, cursor) {
 				_ = normalizedBuf.WriteByte(endTag)
 				cursor += len(endTagUrl)
-			} else if hasPrefix(payload, sepTagUrl, cursor) {
+			} else if hasEscape(payload, sepTagUrl, cursor) {
 				_ = normalizedBuf.WriteByte(sepTag)
 				cursor += len(sepTagUrl)
 			} else {
@@ -377,6 +1167,44 @@ func normalize(payload []byte, encodingFlags RandomizerEncoding) []byte {
 	return result
 }
 
+// isWellFormedTag reports whether tag is expanded rather than kept literally.
+func isWellFormedTag(tag []byte) bool {
+	tag = tag[len(startTag):]
+	if bytes.HasPrefix(tag, startTagOpt) {
+		tag = tag[len(startTagOpt):]
+	}
+	return len(tag) == 0 || tag[0] == sepTag
+}
+
+// findTagEnd returns the index of the '}' closing the tag at payload's start.
+func findTagEnd(payload []byte) int {
+	depth := 0
+	for i := len(startTag); i < len(payload); i++ {
+		switch payload[i] {
+		case '{':
+			if hasPrefix(payload, startTag, i) {
+				depth++
+			}
+		case endTag:
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+func (e *FastEngine) expandArg(arg []byte, state *callState) []byte {
+	if !bytes.Contains(arg, startTag) {
+		return arg
+	}
+	buffer := bytebufferpool.Get()
+	defer bytebufferpool.Put(buffer)
+	e.expandTags(arg, buffer, RandomizerEncodingNone, state, false)
+	return append([]byte(nil), buffer.Bytes()...)
+}
+
 func hasPrefix(slice, prefix []byte, pos int) bool {
 	if pos+len(prefix) > len(slice) {
 		return false
@@ -384,8 +1212,34 @@ func hasPrefix(slice, prefix []byte, pos int) bool {
 	return bytes.Equal(slice[pos:pos+len(prefix)], prefix)
 }
 
-func generateUUID() []byte {
-	uuid, _ := FastUUID()
+// hasEscape is hasPrefix for a percent escape in either case.
+func hasEscape(slice, escape []byte, pos int) bool {
+	if pos+len(escape) > len(slice) {
+		return false
+	}
+	return bytes.EqualFold(slice[pos:pos+len(escape)], escape)
+}
+
+// generateUUID emits a version 4 UUID, or v7 or v1 as version asks.
+func generateUUID(r Source, version []byte) []byte {
+	uuid := randBytes(r, 16)
+	switch {
+	case bytes.EqualFold(version, []byte("v7")):
+		ms := uint64(time.Now().UnixMilli())
+		for i := range 6 {
+			uuid[i] = byte(ms >> (40 - 8*i))
+		}
+		uuid[6] = (uuid[6] & 0x0f) | 0x70
+	case bytes.EqualFold(version, []byte("v1")):
+		ts := uuidV1Timestamp(time.Now())
+		binary.BigEndian.PutUint32(uuid[0:4], uint32(ts))
+		binary.BigEndian.PutUint16(uuid[4:6], uint16(ts>>32))
+		binary.BigEndian.PutUint16(uuid[6:8], uint16(ts>>48)&0x0fff|0x1000)
+		uuid[10] |= 0x01
+	default:
+		uuid[6] = (uuid[6] & 0x0f) | 0x40
+	}
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
 	b := make([]byte, 36)
 	hex.Encode(b[0:8], uuid[0:4])
 	b[8] = '-'
@@ -399,6 +1253,14 @@ func generateUUID() []byte {
 	return b
 }
 
+// uuidEpochOffset is the 100ns intervals from 1582-10-15 to the Unix epoch.
+const uuidEpochOffset = 0x01b21dd213814000
+
+// uuidV1Timestamp returns t as a version 1 UUID timestamp.
+func uuidV1Timestamp(t time.Time) uint64 {
+	return (uint64(t.UnixNano()/100) + uuidEpochOffset) & (1<<60 - 1)
+}
+
 func parseLengthFast(b []byte) (int, bool) {
 	if len(b) == 1 {
 		c := b[0]
@@ -412,14 +1274,64 @@ func parseLengthFast(b []byte) (int, bool) {
 			return int(c1-'0')*10 + int(c2-'0'), true
 		}
 	}
-	return 0, false
+	// Longer lengths only fit within bounds raised by WithMaxLength or
+	// WithKeywordLengthBounds.
+	if len(b) < 3 || len(b) > 9 {
+		return 0, false
+	}
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+// isSizedKeyword reports whether keyword takes a byte size such as 1k.
+func isSizedKeyword(upcased string) bool {
+	switch upcased {
+	case "BYTES", "HEX", "CYCLIC", "HEXDUMP", "BYTESBIAS", "MAGIC":
+		return true
+	}
+	return false
+}
+
+// parseSizeSuffix parses byte sizes such as "1k", "2m" or "1g".
+func parseSizeSuffix(b []byte) (int, bool) {
+	if len(b) < 2 || len(b) > 8 {
+		return 0, false
+	}
+	var multiplier int
+	switch b[len(b)-1] {
+	case 'k', 'K':
+		multiplier = 1 << 10
+	case 'm', 'M':
+		multiplier = 1 << 20
+	case 'g', 'G':
+		multiplier = 1 << 30
+	default:
+		return 0, false
+	}
+	n := 0
+	for _, c := range b[:len(b)-1] {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return n * multiplier, true
 }
 
-func generateRandomHex(byteLength, defaultLen int) []byte {
+func generateRandomHex(r Source, byteLength, defaultLen int) []byte {
 	if byteLength <= 0 {
 		byteLength = defaultLen
 	}
-	srcBytes := Bytes(byteLength)
+	srcBytes := randBytes(r, byteLength)
 	hexBytes := make([]byte, byteLength*2)
 	hex.Encode(hexBytes, srcBytes)
 	return hexBytes
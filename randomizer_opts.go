@@ -1,6 +1,18 @@
 package fastrand
 
-import "strings"
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
 type Engine interface {
 	Randomizer([]byte) []byte
@@ -11,52 +23,177 @@ type FastEngine struct {
 	defaultLength         int
 	minLength             int
 	maxLength             int
+	keywordLengthBounds   map[string]lengthBounds
+	maxByteLength         int
+	bufferHint            int
+	src                   Source
+	defaultCharset        CharsList
+	injectSequences       []string
+	sqliPayloads          []string
+	xssPayloads           []string
+	methodWeights         map[string]float64
 	inputEncoding         RandomizerEncoding
 	outputEncoding        RandomizerEncoding
 	rangesEnabled         bool
 	keywordChoicesEnabled bool
 	lengthChoicesEnabled  bool
+	preserveOnError       bool
+	strictRanges          bool
+	minEntropy            float64
+	dropEmptyDocs         bool
+	collapseWhitespace    bool
+	globalPrefix          string
+	globalSuffix          string
 	enabledKeywords       map[string]bool
+	keywordsToEnable      map[string]struct{}
+	keywordsToDisable     map[string]struct{}
 	mailProviders         []string
+	noEmbeddedProviders   bool
+	maxEmailLength        int
+	emailDomainMode       EmailDomainMode
+	mimeTypes             map[string][]string
+	palettes              map[string][]string
+	timeRangeStart        time.Time
+	timeRangeEnd          time.Time
 	customCharsets        map[string][]byte
-	customKeywords        map[string]CustomKeywordGenerator
+	customKeywords        map[string]keywordGenerator
+	aliases               map[string]string
+	pools                 map[string]*valuePool
+	poolCapacity          int
+	stateResets           []func()
+	loadErrs              []func() error
+	err                   error
 }
 
 type Option func(*FastEngine)
 
-func NewEngine(opts ...Option) *FastEngine {
-	enabledKeywords := make(map[string]bool, len(allKeywords))
+// EmailDomainMode selects how EMAIL builds the domain of an address.
+type EmailDomainMode int
+
+const (
+	// EmailDomainProviders picks the domain from the mail providers.
+	EmailDomainProviders EmailDomainMode = iota
+	// EmailDomainRandom builds the domain from random labels and a TLD.
+	EmailDomainRandom
+)
+
+// defaultEnabledKeywords is shared by engines and must never be written to.
+var defaultEnabledKeywords = func() map[string]bool {
+	enabled := make(map[string]bool, len(allKeywords))
 	for _, kw := range allKeywords {
-		enabledKeywords[kw] = true
+		enabled[kw] = true
 	}
+	return enabled
+}()
 
+// NewEngine returns an engine configured by opts. Without options it only
+// allocates the engine itself: the keyword table and data lists are shared
+// defaults, and the maps options write to are created on first use.
+func NewEngine(opts ...Option) *FastEngine {
 	e := &FastEngine{
 		defaultLength:         16,
 		minLength:             1,
 		maxLength:             99,
+		maxByteLength:         16 << 20,
+		src:                   FastSource,
+		defaultCharset:        CharsAll,
+		injectSequences:       defaultInjectSequences,
+		sqliPayloads:          SQLiPayloads,
+		xssPayloads:           XSSPayloads,
+		methodWeights:         defaultMethodWeights,
 		inputEncoding:         RandomizerEncodingURL | RandomizerEncodingHTML,
 		outputEncoding:        RandomizerEncodingNone,
 		rangesEnabled:         true,
 		keywordChoicesEnabled: true,
 		lengthChoicesEnabled:  true,
-		enabledKeywords:       enabledKeywords,
-		mailProviders:         SafeMailProviders,
-		customCharsets:        make(map[string][]byte),
-		customKeywords:        make(map[string]CustomKeywordGenerator),
+		enabledKeywords:       defaultEnabledKeywords,
+		mimeTypes:             defaultMimeGroups,
+		timeRangeStart:        defaultTimeRangeStart,
+		timeRangeEnd:          defaultTimeRangeEnd,
+		poolCapacity:          defaultPoolCapacity,
 	}
 
 	for _, opt := range opts {
 		opt(e)
 	}
+	e.resolveKeywordToggles()
 
 	return e
 }
 
+// resolveKeywordToggles applies the keyword toggles after all options ran.
+func (e *FastEngine) resolveKeywordToggles() {
+	if len(e.keywordsToDisable) == 0 && len(e.keywordsToEnable) == 0 {
+		return
+	}
+	e.enabledKeywords = maps.Clone(e.enabledKeywords)
+	for kw := range e.keywordsToDisable {
+		e.enabledKeywords[kw] = false
+	}
+	for kw := range e.keywordsToEnable {
+		if _, builtin := e.enabledKeywords[kw]; builtin {
+			e.enabledKeywords[kw] = true
+		}
+	}
+}
+
+// withSource returns a copy of the engine, sharing its pools, drawing from src.
+func (e *FastEngine) withSource(src Source) *FastEngine {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	if e.pools == nil {
+		e.pools = make(map[string]*valuePool)
+	}
+	c := *e
+	c.src = src
+	return &c
+}
+
+// Err returns the first error hit while applying options, such as a data
+// file that could not be loaded, or else the first error hit while reading a
+// WithFileList file on first use.
+func (e *FastEngine) Err() error {
+	if e.err != nil {
+		return e.err
+	}
+	for _, loadErr := range e.loadErrs {
+		if err := loadErr(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *FastEngine) setErr(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// Reset restores the default configuration, discarding every option. Use
+// ResetState to keep the options and only start the engine's runtime state
+// over.
 func (e *FastEngine) Reset() {
 	freshEngine := NewEngine()
 	*e = *freshEngine
 }
 
+// ResetState clears what the engine accumulated while running but keeps its
+// options: a WithSeed source starts its sequence over, WithFileList files
+// are read again on next use and REMEMBER pools are emptied. Neither Reset
+// nor ResetState may run concurrently with expansion.
+func (e *FastEngine) ResetState() {
+	if src, ok := e.src.(*seededSource); ok {
+		src.reset()
+	}
+	for _, reset := range e.stateResets {
+		reset()
+	}
+	poolsMu.Lock()
+	e.pools = nil
+	poolsMu.Unlock()
+}
+
 func WithDefaultLength(length int) Option {
 	return func(e *FastEngine) {
 		if length > 0 {
@@ -81,10 +218,208 @@ func WithMaxLength(length int) Option {
 	}
 }
 
+// WithStrictRanges stops inverted ranges such as {RAND;10-5} from being
+// swapped; such tags are then written out literally and rejected by Validate.
+func WithStrictRanges() Option {
+	return func(e *FastEngine) {
+		e.strictRanges = true
+	}
+}
+
+// WithGlobalPrefix prepends s to every expansion. It is written after output
+// encoding, so it appears exactly as given.
+func WithGlobalPrefix(s string) Option {
+	return func(e *FastEngine) {
+		e.globalPrefix = s
+	}
+}
+
+// WithGlobalSuffix appends s to every expansion, like WithGlobalPrefix.
+func WithGlobalSuffix(s string) Option {
+	return func(e *FastEngine) {
+		e.globalSuffix = s
+	}
+}
+
+// WithSource makes the engine draw all of its randomness from src instead of
+// FastSource, including WithChoiceSet, WithWeightedList and WithFileList
+// keywords. Generators passed to WithCustomKeyword are not affected.
+func WithSource(src Source) Option {
+	return func(e *FastEngine) {
+		if src != nil {
+			e.src = src
+		}
+	}
+}
+
+// WithSeed makes the engine deterministic: engines built with the same seed
+// and options expand a template to the same output. WithChoiceSet,
+// WithWeightedList and WithFileList keywords draw from the seeded source as
+// well; only generators passed to WithCustomKeyword are not affected.
+func WithSeed(seed uint64) Option {
+	return func(e *FastEngine) {
+		e.src = newSeededSource(seed)
+	}
+}
+
+// WithInjectSequences replaces the sequences CRLFINJECT inserts. Sequences
+// are templates themselves, so they can contain tags.
+func WithInjectSequences(sequences ...string) Option {
+	return func(e *FastEngine) {
+		if len(sequences) > 0 {
+			e.injectSequences = sequences
+		}
+	}
+}
+
+// WithSQLiPayloads replaces the embedded SQLiPayloads list used by SQLI.
+func WithSQLiPayloads(payloads []string) Option {
+	return func(e *FastEngine) {
+		if len(payloads) > 0 {
+			e.sqliPayloads = payloads
+		}
+	}
+}
+
+// WithXssPayloads replaces the embedded XSSPayloads list used by XSS.
+func WithXssPayloads(payloads []string) Option {
+	return func(e *FastEngine) {
+		if len(payloads) > 0 {
+			e.xssPayloads = payloads
+		}
+	}
+}
+
+// WithMethodWeights replaces the methods METHOD picks from and their weights.
+// Method names are upper-cased and non-positive weights are dropped; a map
+// without any positive weight is rejected through Err.
+func WithMethodWeights(weights map[string]float64) Option {
+	methodWeights := make(map[string]float64, len(weights))
+	for method, weight := range weights {
+		if weight > 0 {
+			methodWeights[strings.ToUpper(method)] = weight
+		}
+	}
+	return func(e *FastEngine) {
+		if len(methodWeights) == 0 {
+			e.setErr(fmt.Errorf("fastrand: method weights must contain a positive weight"))
+			return
+		}
+		e.methodWeights = methodWeights
+	}
+}
+
+// WithPalette registers a palette COLOR can pick from by name, replacing an
+// embedded palette of the same name. Colors are given as "#RGB" or "#RRGGBB",
+// with or without '#'; an empty palette or one with an invalid color is
+// rejected through Err.
+func WithPalette(name string, colors []string) Option {
+	normalized := make([]string, 0, len(colors))
+	var invalid error
+	for _, color := range colors {
+		hexColor, ok := normalizeHexColor(color)
+		if !ok {
+			invalid = fmt.Errorf("fastrand: palette %q has invalid color %q", name, color)
+			break
+		}
+		normalized = append(normalized, hexColor)
+	}
+	return func(e *FastEngine) {
+		switch {
+		case invalid != nil:
+			e.setErr(invalid)
+			return
+		case len(normalized) == 0:
+			e.setErr(fmt.Errorf("fastrand: palette %q must not be empty", name))
+			return
+		}
+		if e.palettes == nil {
+			e.palettes = make(map[string][]string)
+		}
+		e.palettes[strings.ToLower(name)] = normalized
+	}
+}
+
+// WithBufferHint sets how many bytes beyond the template length the output
+// buffer reserves up front. Without it the engine reserves the length each
+// tag asks for, or the default length, up to WithMaxByteLength in total.
+func WithBufferHint(n int) Option {
+	return func(e *FastEngine) {
+		if n > 0 {
+			e.bufferHint = n
+		}
+	}
+}
+
+// WithKeywordLengthBounds replaces the global length bounds for a single
+// keyword: its lengths and ranges are accepted within [min, max] and its
+// final length is clamped into them. Bounds with min < 1 or min > max are
+// rejected through Err.
+func WithKeywordLengthBounds(keyword string, min, max int) Option {
+	return func(e *FastEngine) {
+		if min < 1 || min > max {
+			e.setErr(fmt.Errorf("fastrand: invalid length bounds [%d, %d] for %s", min, max, strings.ToUpper(keyword)))
+			return
+		}
+		if e.keywordLengthBounds == nil {
+			e.keywordLengthBounds = make(map[string]lengthBounds)
+		}
+		e.keywordLengthBounds[strings.ToUpper(keyword)] = lengthBounds{min, max}
+	}
+}
+
+// WithMinEntropy makes RandomizeStrict reject templates whose EntropyBits
+// estimate is below bits with ErrLowEntropy. Literal text adds nothing to the
+// estimate, so only the generated parts count.
+func WithMinEntropy(bits float64) Option {
+	return func(e *FastEngine) {
+		e.minEntropy = bits
+	}
+}
+
+// WithDropEmptyDocs makes RandomizerDocs skip the empty documents produced by
+// SPLIT markers at the edges of a template or next to each other.
+func WithDropEmptyDocs() Option {
+	return func(e *FastEngine) {
+		e.dropEmptyDocs = true
+	}
+}
+
+// WithCollapseWhitespace replaces every run of ASCII whitespace in the
+// expanded template with a single space before output encoding. Whitespace
+// inside nested tag arguments and the global prefix and suffix is kept.
+func WithCollapseWhitespace() Option {
+	return func(e *FastEngine) {
+		e.collapseWhitespace = true
+	}
+}
+
+func WithMaxByteLength(length int) Option {
+	return func(e *FastEngine) {
+		if length > 0 {
+			e.maxByteLength = length
+		}
+	}
+}
+
 func WithDisabledKeywords(keywords ...string) Option {
 	return func(e *FastEngine) {
+		if e.keywordsToDisable == nil {
+			e.keywordsToDisable = make(map[string]struct{}, len(keywords))
+		}
+		for _, kw := range keywords {
+			e.keywordsToDisable[strings.ToUpper(kw)] = struct{}{}
+		}
+	}
+}
+
+func WithEnabledKeywords(keywords ...string) Option {
+	return func(e *FastEngine) {
+		if e.keywordsToEnable == nil {
+			e.keywordsToEnable = make(map[string]struct{}, len(keywords))
+		}
 		for _, kw := range keywords {
-			e.enabledKeywords[strings.ToUpper(kw)] = false
+			e.keywordsToEnable[strings.ToUpper(kw)] = struct{}{}
 		}
 	}
 }
@@ -97,15 +432,264 @@ func WithMailProviders(providers []string) Option {
 	}
 }
 
+// WithoutEmbeddedProviders stops EMAIL from using the embedded
+// SafeMailProviders list. Addresses then use the providers given to
+// WithMailProviders, or example.com when there are none.
+func WithoutEmbeddedProviders() Option {
+	return func(e *FastEngine) {
+		e.noEmbeddedProviders = true
+	}
+}
+
+// WithEmailDomainMode selects where EMAIL domains come from. Unknown modes
+// are ignored.
+func WithEmailDomainMode(mode EmailDomainMode) Option {
+	return func(e *FastEngine) {
+		if mode == EmailDomainProviders || mode == EmailDomainRandom {
+			e.emailDomainMode = mode
+		}
+	}
+}
+
+func WithMaxEmailLength(length int) Option {
+	return func(e *FastEngine) {
+		if length > 0 {
+			e.maxEmailLength = length
+		}
+	}
+}
+
+func WithTimeRange(start, end time.Time) Option {
+	return func(e *FastEngine) {
+		if !end.Before(start) {
+			e.timeRangeStart = start
+			e.timeRangeEnd = end
+		}
+	}
+}
+
+func WithMimeTypes(types []string) Option {
+	return func(e *FastEngine) {
+		if len(types) > 0 {
+			e.mimeTypes = groupMimeTypes(types)
+		}
+	}
+}
+
+// WithDefaultCharset sets the charset of bare tags such as {RAND;8} and of
+// unknown or disabled keywords. An empty charset is rejected through Err and
+// leaves CharsAll in place.
+func WithDefaultCharset(charset []byte) Option {
+	return func(e *FastEngine) {
+		if len(charset) == 0 {
+			e.setErr(fmt.Errorf("fastrand: default charset must not be empty"))
+			return
+		}
+		e.defaultCharset = charset
+	}
+}
+
+// WithPoolCapacity sets how many values each REMEMBER pool keeps. Once a pool
+// is full, every new value replaces the oldest one.
+func WithPoolCapacity(capacity int) Option {
+	return func(e *FastEngine) {
+		if capacity <= 0 {
+			e.setErr(fmt.Errorf("fastrand: pool capacity must be positive, got %d", capacity))
+			return
+		}
+		e.poolCapacity = capacity
+	}
+}
+
 func WithCustomCharset(keyword string, charset []byte) Option {
 	return func(e *FastEngine) {
+		if e.customCharsets == nil {
+			e.customCharsets = make(map[string][]byte)
+		}
 		e.customCharsets[strings.ToUpper(keyword)] = charset
 	}
 }
 
 func WithCustomKeyword(keyword string, generator CustomKeywordGenerator) Option {
 	return func(e *FastEngine) {
-		e.customKeywords[strings.ToUpper(keyword)] = generator
+		e.setKeywordGenerator(keyword, func(_ Source, length int) []byte {
+			return generator(length)
+		})
+	}
+}
+
+func (e *FastEngine) setKeywordGenerator(keyword string, generator keywordGenerator) {
+	if e.customKeywords == nil {
+		e.customKeywords = make(map[string]keywordGenerator)
+	}
+	e.customKeywords[strings.ToUpper(keyword)] = generator
+}
+
+func WithChoiceSet(keyword string, values []string) Option {
+	choices := make([][]byte, 0, len(values))
+	for _, v := range values {
+		choices = append(choices, []byte(v))
+	}
+	return func(e *FastEngine) {
+		e.setKeywordGenerator(keyword, func(r Source, _ int) []byte {
+			if len(choices) == 0 {
+				return []byte{}
+			}
+			return pick(r, choices)
+		})
+	}
+}
+
+// WithWeightedList registers keyword to pick a value from the file at path.
+// Each line holds a value and an optional weight ("value weight"), defaulting
+// to 1. Blank lines, '#' comments and malformed lines are skipped. Load
+// errors are reported by Err.
+func WithWeightedList(keyword, path string) Option {
+	return func(e *FastEngine) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			e.setErr(fmt.Errorf("fastrand: failed to load weighted list %q: %w", path, err))
+			return
+		}
+		values, weights := parseWeightedList(string(data))
+		if len(values) == 0 {
+			e.setErr(fmt.Errorf("fastrand: weighted list %q has no valid entries", path))
+			return
+		}
+		e.setKeywordGenerator(keyword, func(r Source, _ int) []byte {
+			return weightedChoice(values, weights, func() float64 { return randFloat64(r) })
+		})
+	}
+}
+
+// WithFileList registers keyword to pick a random line from the file at
+// path. The file is only checked for existence up front, and a missing file
+// is reported by Err; its lines are read once on first use, with surrounding
+// whitespace trimmed and blank lines skipped, and a failed read is reported
+// by Err from then on. Lines have no length limit. An empty file makes
+// keyword expand to nothing.
+func WithFileList(keyword, path string) Option {
+	return func(e *FastEngine) {
+		if _, err := os.Stat(path); err != nil {
+			e.setErr(fmt.Errorf("fastrand: failed to load file list %q: %w", path, err))
+			return
+		}
+		// Each engine gets its own cache, even when opts are reused.
+		list := &fileList{path: path}
+		e.setKeywordGenerator(keyword, func(r Source, _ int) []byte {
+			lines := list.load()
+			if len(lines) == 0 {
+				return []byte{}
+			}
+			return pick(r, lines)
+		})
+		e.stateResets = append(e.stateResets, list.reset)
+		e.loadErrs = append(e.loadErrs, list.loadErr)
+	}
+}
+
+// fileList lazily reads the lines of a WithFileList file.
+type fileList struct {
+	path  string
+	once  sync.Once
+	lines [][]byte
+	mu    sync.Mutex
+	err   error
+}
+
+func (l *fileList) load() [][]byte {
+	l.once.Do(func() {
+		lines, err := readLines(l.path)
+		l.lines = lines
+		if err != nil {
+			l.mu.Lock()
+			l.err = fmt.Errorf("fastrand: failed to read file list %q: %w", l.path, err)
+			l.mu.Unlock()
+		}
+	})
+	return l.lines
+}
+
+func (l *fileList) loadErr() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// reset drops the loaded lines so the next load reads the file again.
+func (l *fileList) reset() {
+	l.once = sync.Once{}
+	l.lines = nil
+	l.mu.Lock()
+	l.err = nil
+	l.mu.Unlock()
+}
+
+// readLines returns the trimmed, non-blank lines of the file at path.
+func readLines(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines [][]byte
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
+func parseWeightedList(data string) ([][]byte, []float64) {
+	var values [][]byte
+	var weights []float64
+	for _, line := range splitLines(data) {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		weight := 1.0
+		switch len(fields) {
+		case 1:
+		case 2:
+			w, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil || !(w > 0) || math.IsInf(w, 0) {
+				continue
+			}
+			weight = w
+		default:
+			continue
+		}
+		values = append(values, []byte(fields[0]))
+		weights = append(weights, weight)
+	}
+	return values, weights
+}
+
+func WithKeywordAlias(alias, target string) Option {
+	return func(e *FastEngine) {
+		alias, target = strings.ToUpper(alias), strings.ToUpper(target)
+		if alias == "" || target == "" {
+			return
+		}
+		for kw, ok := target, true; ok; kw, ok = e.aliases[kw] {
+			if kw == alias {
+				e.setErr(fmt.Errorf("fastrand: keyword alias %s -> %s would form a cycle", alias, target))
+				return
+			}
+		}
+		if e.aliases == nil {
+			e.aliases = make(map[string]string)
+		}
+		e.aliases[alias] = target
 	}
 }
 
@@ -138,3 +722,9 @@ func WithLengthChoices(enabled bool) Option {
 		e.lengthChoicesEnabled = enabled
 	}
 }
+
+func WithPreserveOnError() Option {
+	return func(e *FastEngine) {
+		e.preserveOnError = true
+	}
+}
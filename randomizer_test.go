@@ -2,8 +2,16 @@ package fastrand_test
 
 import (
 	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/SyNdicateFoundation/fastrand"
@@ -282,10 +290,13 @@ func TestRandomizer(t *testing.T) {
 			}},
 		},
 		{
-			name:        "Length Range Invalid (Fallback)",
+			name:        "Length Range Inverted (Swapped)",
 			input:       "Data: {RAND;15-5;ABU}",
-			expectedLen: map[string]int{"{RAND;15-5;ABU}": 16},
+			expectedLen: map[string]int{"{RAND;15-5;ABU}": -1},
 			checkFunc: map[string]func(testing.TB, []byte){"{RAND;15-5;ABU}": func(tb testing.TB, b []byte) {
+				if len(b) < 5 || len(b) > 15 {
+					tb.Errorf("Expected length between 5 and 15, got %d", len(b))
+				}
 				checkCharset(tb, b, fastrand.CharsAlphabetUpper)
 			}},
 		},
@@ -558,6 +569,73 @@ func TestEngine(t *testing.T) {
 		}
 	})
 
+	t.Run("ResetState", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithSeed(9),
+			fastrand.WithDefaultLength(5),
+			fastrand.WithDisabledKeywords("UUID"),
+		)
+		first := engine.RandomizerString("{RAND} {RAND;8;DIGIT}")
+		if second := engine.RandomizerString("{RAND} {RAND;8;DIGIT}"); second == first {
+			t.Fatalf("Pre-condition failed: expected the seeded sequence to advance, got %q twice", first)
+		}
+
+		engine.ResetState()
+		if restarted := engine.RandomizerString("{RAND} {RAND;8;DIGIT}"); restarted != first {
+			t.Errorf("Expected the sequence to restart after ResetState, got %q, want %q", restarted, first)
+		}
+		if got := len(engine.RandomizerString("{RAND}")); got != 5 {
+			t.Errorf("Expected the default length to survive ResetState, got %d", got)
+		}
+		if uuidRegex.MatchString(engine.RandomizerString("{RAND;UUID}")) {
+			t.Error("Expected UUID to stay disabled after ResetState")
+		}
+	})
+
+	t.Run("ResetStateReloadsFileLists", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "names.txt")
+		if err := os.WriteFile(path, []byte("alice\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		engine := fastrand.NewEngine(fastrand.WithFileList("NAME", path))
+		if got := engine.RandomizerString("{RAND;;NAME}"); got != "alice" {
+			t.Fatalf("Expected alice, got %q", got)
+		}
+		if err := os.WriteFile(path, []byte("bob\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := engine.RandomizerString("{RAND;;NAME}"); got != "alice" {
+			t.Errorf("Expected the loaded list to be kept until ResetState, got %q", got)
+		}
+		engine.ResetState()
+		if got := engine.RandomizerString("{RAND;;NAME}"); got != "bob" {
+			t.Errorf("Expected ResetState to reload the file, got %q", got)
+		}
+	})
+
+	t.Run("FileListPerEngine", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "names.txt")
+		if err := os.WriteFile(path, []byte("alice\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		opt := fastrand.WithFileList("NAME", path)
+		first := fastrand.NewEngine(opt)
+		if got := first.RandomizerString("{RAND;;NAME}"); got != "alice" {
+			t.Fatalf("Expected alice, got %q", got)
+		}
+		if err := os.WriteFile(path, []byte("bob\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		second := fastrand.NewEngine(opt)
+		if got := second.RandomizerString("{RAND;;NAME}"); got != "bob" {
+			t.Errorf("Expected an engine sharing the option to read the file itself, got %q", got)
+		}
+		second.ResetState()
+		if got := first.RandomizerString("{RAND;;NAME}"); got != "alice" {
+			t.Errorf("Expected ResetState to leave other engines alone, got %q", got)
+		}
+	})
+
 	t.Run("WithOptions_Length", func(t *testing.T) {
 		engine := fastrand.NewEngine(
 			fastrand.WithDefaultLength(10),
@@ -716,3 +794,1286 @@ func TestDefaultEngine(t *testing.T) {
 		}
 	})
 }
+
+func TestKeywordAlias(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithKeywordAlias("GUID", "UUID"))
+		result := engine.RandomizerString("{RAND;GUID}")
+		if !uuidRegex.MatchString(result) {
+			t.Errorf("Expected GUID alias to produce a UUID, got %q", result)
+		}
+	})
+
+	t.Run("Chained", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithKeywordAlias("LOWER", "ABL"),
+			fastrand.WithKeywordAlias("small", "lower"),
+		)
+		result := engine.RandomizerString("{RAND;12;SMALL}")
+		if len(result) != 12 {
+			t.Errorf("Expected length 12, got %d", len(result))
+		}
+		checkCharset(t, []byte(result), fastrand.CharsAlphabetLower)
+	})
+
+	t.Run("CustomKeywordTarget", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithCustomKeyword("SKU", func(length int) []byte { return []byte("SKU") }),
+			fastrand.WithKeywordAlias("PRODUCT", "SKU"),
+		)
+		if result := engine.RandomizerString("{RAND;PRODUCT}"); result != "SKU" {
+			t.Errorf("Expected alias to custom keyword to produce %q, got %q", "SKU", result)
+		}
+	})
+
+	t.Run("Choice", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithKeywordAlias("GUID", "UUID"))
+		result := engine.RandomizerString("{RAND;GUID,GUID}")
+		if !uuidRegex.MatchString(result) {
+			t.Errorf("Expected alias to be accepted as a keyword choice, got %q", result)
+		}
+	})
+
+	t.Run("Cyclic", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithKeywordAlias("A", "B"),
+			fastrand.WithKeywordAlias("B", "DIGIT"),
+			fastrand.WithKeywordAlias("DIGIT", "A"),
+		)
+		result := engine.RandomizerString("{RAND;10;A}")
+		if len(result) != 10 {
+			t.Fatalf("Expected length 10, got %d", len(result))
+		}
+		checkCharset(t, []byte(result), fastrand.CharsDigits)
+		if engine.Err() == nil {
+			t.Error("Expected the cyclic alias to be reported")
+		}
+
+		engine = fastrand.NewEngine(fastrand.WithKeywordAlias("A", "B"), fastrand.WithKeywordAlias("B", "A"))
+		if engine.Err() == nil {
+			t.Error("Expected A -> B -> A to be reported")
+		}
+		if result := engine.RandomizerString("{RAND;4;A}"); len(result) != 4 {
+			t.Errorf("Expected the remaining alias to expand, got %q", result)
+		}
+	})
+
+	t.Run("DisabledTarget", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithKeywordAlias("GUID", "UUID"),
+			fastrand.WithDisabledKeywords("UUID"),
+		)
+		result := engine.RandomizerString("{RAND;GUID}")
+		if uuidRegex.MatchString(result) || len(result) != 16 {
+			t.Errorf("Expected alias to disabled keyword to fall back to default output, got %q", result)
+		}
+	})
+}
+
+func TestEmptyFields(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithCustomCharset("ABR", []byte("x")))
+	testCases := []struct {
+		input    string
+		length   int
+		charset  []byte
+		expected string
+	}{
+		{input: "{RAND}", length: 16, charset: fastrand.CharsAll},
+		{input: "{RAND;}", length: 16, charset: fastrand.CharsAll},
+		{input: "{RAND;;}", length: 16, charset: fastrand.CharsAll},
+		{input: "{RAND;;;}", length: 16, charset: fastrand.CharsAll},
+		{input: "{RAND;8;}", length: 8, charset: fastrand.CharsAll},
+		{input: "{RAND;;ABL}", length: 16, charset: fastrand.CharsAlphabetLower},
+		{input: "{RAND;8;ABL}", length: 8, charset: fastrand.CharsAlphabetLower},
+		{input: "{RANDX}", expected: "{RANDX}"},
+		{input: "{RANDOMX;8}", expected: "{RANDOMX;8}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result := engine.RandomizerString(tc.input)
+			if tc.expected != "" {
+				if result != tc.expected {
+					t.Errorf("Expected literal %q, got %q", tc.expected, result)
+				}
+				return
+			}
+			if len(result) != tc.length {
+				t.Errorf("Expected length %d, got %d (%q)", tc.length, len(result), result)
+			}
+			checkCharset(t, []byte(result), tc.charset)
+			if strings.Trim(result, "x") == "" {
+				t.Errorf("Expected empty keyword to ignore the ABR charset, got %q", result)
+			}
+		})
+	}
+}
+
+func TestChoiceSet(t *testing.T) {
+	t.Run("Members", func(t *testing.T) {
+		values := []string{"red", "green", "blue"}
+		engine := fastrand.NewEngine(fastrand.WithChoiceSet("color", values))
+		values[0] = "mutated"
+
+		seen := make(map[string]int)
+		for i := 0; i < 300; i++ {
+			seen[engine.RandomizerString("{RAND;COLOR}")]++
+		}
+		for value := range seen {
+			if value != "red" && value != "green" && value != "blue" {
+				t.Errorf("Unexpected value %q outside of the choice set", value)
+			}
+		}
+		if len(seen) != 3 {
+			t.Errorf("Expected all 3 values to be chosen, got %v", seen)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithChoiceSet("NOTHING", nil))
+		if result := engine.RandomizerString("[{RAND;NOTHING}]"); result != "[]" {
+			t.Errorf("Expected empty choice set to produce nothing, got %q", result)
+		}
+	})
+}
+
+func TestSizeSuffix(t *testing.T) {
+	t.Run("Kilobytes", func(t *testing.T) {
+		if result := fastrand.Randomizer([]byte("{RAND;1k;BYTES}")); len(result) != 1024 {
+			t.Errorf("Expected 1024 bytes, got %d", len(result))
+		}
+		if result := fastrand.Randomizer([]byte("{RAND;4K;HEX}")); len(result) != 8192 {
+			t.Errorf("Expected 8192 hex chars, got %d", len(result))
+		}
+	})
+
+	t.Run("Megabytes", func(t *testing.T) {
+		if result := fastrand.Randomizer([]byte("{RAND;2m;BYTES}")); len(result) != 2*1024*1024 {
+			t.Errorf("Expected 2MiB, got %d", len(result))
+		}
+	})
+
+	t.Run("Cap", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithMaxByteLength(4096))
+		if result := engine.Randomizer([]byte("{RAND;4k;BYTES}")); len(result) != 4096 {
+			t.Errorf("Expected size at the cap to be accepted, got %d", len(result))
+		}
+		if result := engine.Randomizer([]byte("{RAND;5k;BYTES}")); len(result) != 16 {
+			t.Errorf("Expected size above the cap to fall back to the default length, got %d", len(result))
+		}
+		if result := fastrand.Randomizer([]byte("{RAND;1g;BYTES}")); len(result) != 16 {
+			t.Errorf("Expected 1g to exceed the default cap, got %d", len(result))
+		}
+	})
+
+	t.Run("CharacterKeyword", func(t *testing.T) {
+		if result := fastrand.RandomizerString("{RAND;1k}"); len(result) != 16 {
+			t.Errorf("Expected suffix without keyword to fall back to the default length, got %d", len(result))
+		}
+		if result := fastrand.RandomizerString("{RAND;1k;ABL}"); len(result) != 16 {
+			t.Errorf("Expected suffix on character keyword to fall back to the default length, got %d", len(result))
+		}
+	})
+}
+
+func TestPreserveOnError(t *testing.T) {
+	nilKeyword := func(int) []byte { return nil }
+	panicKeyword := func(int) []byte { panic("boom") }
+
+	t.Run("NilOutput", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithPreserveOnError(),
+			fastrand.WithCustomKeyword("NOTHING", nilKeyword),
+		)
+		if result := engine.RandomizerString("a{RAND;8;NOTHING}b"); result != "a{RAND;8;NOTHING}b" {
+			t.Errorf("Expected literal tag to be preserved, got %q", result)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithPreserveOnError(),
+			fastrand.WithCustomKeyword("BOOM", panicKeyword),
+		)
+		if result := engine.RandomizerString("{RAND;8;DIGIT}-{RANDOM;BOOM}"); !strings.HasSuffix(result, "-{RANDOM;BOOM}") || len(result) != 8+len("-{RANDOM;BOOM}") {
+			t.Errorf("Expected panicking keyword to be preserved literally, got %q", result)
+		}
+	})
+
+	t.Run("EmptyOutput", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithPreserveOnError(),
+			fastrand.WithCustomKeyword("BLANK", func(int) []byte { return []byte{} }),
+		)
+		if result := engine.RandomizerString("a{RAND;;RECALL;empty}{RAND;BLANK}b"); result != "ab" {
+			t.Errorf("Expected empty outputs to stay empty, got %q", result)
+		}
+	})
+
+	t.Run("InvalidArgument", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithPreserveOnError())
+		if result := engine.RandomizerString("{RAND;;STEP;1;5;0}"); result != "{RAND;;STEP;1;5;0}" {
+			t.Errorf("Expected invalid STEP to be preserved, got %q", result)
+		}
+	})
+
+	t.Run("CSVRowsPanic", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithPreserveOnError(),
+			fastrand.WithCustomKeyword("BOOM", panicKeyword),
+		)
+		result := engine.RandomizerString("{RAND;3;CSVROWS;DIGIT,BOOM}")
+		lines := strings.Split(result, "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Expected 3 rows, got %q", result)
+		}
+		for _, line := range lines {
+			field, rest, _ := strings.Cut(line, ",")
+			if field == "" || strings.Trim(field, "0123456789") != "" || rest != "" {
+				t.Errorf("Expected a digit field and an empty field, got %q", line)
+			}
+		}
+	})
+
+	t.Run("OutputEncoding", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithPreserveOnError(),
+			fastrand.WithOutputEncoding(fastrand.RandomizerEncodingURL),
+			fastrand.WithCustomKeyword("NOTHING", nilKeyword),
+		)
+		if result := engine.RandomizerString("{RAND;NOTHING}"); result != "%7BRAND%3BNOTHING%7D" {
+			t.Errorf("Expected preserved tag to be output encoded, got %q", result)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithCustomKeyword("NOTHING", nilKeyword))
+		if result := engine.RandomizerString("a{RAND;8;NOTHING}b"); result != "ab" {
+			t.Errorf("Expected empty output without WithPreserveOnError, got %q", result)
+		}
+	})
+}
+
+func TestGenerateUnique(t *testing.T) {
+	t.Run("Unique", func(t *testing.T) {
+		values, err := fastrand.GenerateUnique([]byte("tok-{RAND;4;HEX}"), 500)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(values) != 500 {
+			t.Fatalf("Expected 500 values, got %d", len(values))
+		}
+		seen := make(map[string]struct{}, len(values))
+		for _, v := range values {
+			if _, dup := seen[v]; dup {
+				t.Fatalf("Duplicate value %q", v)
+			}
+			seen[v] = struct{}{}
+		}
+	})
+
+	t.Run("LowEntropy", func(t *testing.T) {
+		values, err := fastrand.GenerateUnique([]byte("{RAND;1;DIGIT}"), 20)
+		if err == nil {
+			t.Fatal("Expected an error when the template cannot produce enough unique values")
+		}
+		if len(values) > 10 {
+			t.Errorf("Expected at most 10 unique digits, got %d", len(values))
+		}
+	})
+
+	t.Run("NonPositive", func(t *testing.T) {
+		values, err := fastrand.NewEngine().GenerateUnique([]byte("{RAND}"), 0)
+		if err != nil || len(values) != 0 {
+			t.Errorf("Expected empty result without error, got %v, %v", values, err)
+		}
+	})
+}
+
+func TestEmailOptions(t *testing.T) {
+	t.Run("PlusAddressing", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			result := fastrand.RandomizerString("{RAND;8;EMAIL;plus}")
+			checkEmailFormat(t, []byte(result))
+			local := result[:strings.IndexByte(result, '@')]
+			user, tag, found := strings.Cut(local, "+")
+			if !found || len(user) != 8 || len(tag) == 0 {
+				t.Fatalf("Expected user+tag local part, got %q", result)
+			}
+		}
+	})
+
+	t.Run("LengthCap", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithMailProviders([]string{"example.com"}),
+			fastrand.WithMaxEmailLength(16),
+		)
+		for _, template := range []string{"{RAND;30;EMAIL}", "{RAND;30;EMAIL;plus}"} {
+			result := engine.RandomizerString(template)
+			if len(result) != 16 || !strings.HasSuffix(result, "@example.com") {
+				t.Errorf("%s: expected a 16 char email ending in @example.com, got %q", template, result)
+			}
+		}
+		if result := engine.RandomizerString("{RAND;2;EMAIL}"); result != result[:2]+"@example.com" {
+			t.Errorf("Expected short emails to be left alone, got %q", result)
+		}
+	})
+
+	t.Run("TinyCapKeepsDomain", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithMailProviders([]string{"example.com"}),
+			fastrand.WithMaxEmailLength(5),
+		)
+		result := engine.RandomizerString("{RAND;10;EMAIL;plus}")
+		if len(result) != len("x@example.com") || !strings.HasSuffix(result, "@example.com") {
+			t.Errorf("Expected a single char local part with the domain kept, got %q", result)
+		}
+	})
+
+	tlds := []string{"com", "net", "org", "io", "dev"}
+	domainOf := func(t *testing.T, email string) []string {
+		t.Helper()
+		local, domain, found := strings.Cut(email, "@")
+		if !found || !regexp.MustCompile(`^[a-z]+(\+[a-z]+)?$`).MatchString(local) {
+			t.Fatalf("Expected a valid email, got %q", email)
+		}
+		return strings.Split(domain, ".")
+	}
+
+	t.Run("RandomDomains", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithEmailDomainMode(fastrand.EmailDomainRandom))
+		for depth := 1; depth <= 3; depth++ {
+			for i := 0; i < 50; i++ {
+				labels := domainOf(t, engine.RandomizerString("{RAND;8;EMAIL;"+strconv.Itoa(depth)+"}"))
+				if len(labels) != depth+1 || !slices.Contains(tlds, labels[depth]) {
+					t.Fatalf("Expected %d labels and a known TLD, got %q", depth, labels)
+				}
+			}
+		}
+		if labels := domainOf(t, engine.RandomizerString("{RAND;8;EMAIL}")); len(labels) != 2 {
+			t.Errorf("Expected a single label before the TLD by default, got %q", labels)
+		}
+	})
+
+	t.Run("ProviderSubdomains", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithMailProviders([]string{"example.com"}))
+		if email := engine.RandomizerString("{RAND;8;EMAIL}"); !strings.HasSuffix(email, "@example.com") {
+			t.Errorf("Expected provider mode to be unchanged, got %q", email)
+		}
+		email := engine.RandomizerString("{RAND;8;EMAIL;plus,3}")
+		labels := domainOf(t, email)
+		if len(labels) != 4 || !strings.HasSuffix(email, ".example.com") || !strings.Contains(email, "+") {
+			t.Errorf("Expected two subdomains in front of the provider and a +tag, got %q", email)
+		}
+	})
+
+	t.Run("InvalidDepth", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithEmailDomainMode(fastrand.EmailDomainRandom))
+		for _, depth := range []string{"0", "6", "x"} {
+			if labels := domainOf(t, engine.RandomizerString("{RAND;8;EMAIL;"+depth+"}")); len(labels) != 2 {
+				t.Errorf("Expected depth %q to be ignored, got %q", depth, labels)
+			}
+		}
+	})
+}
+
+func TestCountTags(t *testing.T) {
+	testCases := []struct {
+		template string
+		expected int
+	}{
+		{"", 0},
+		{"no tags here", 0},
+		{"{RAND}", 1},
+		{"a {RAND;8;HEX} b {RANDOM;UUID} c {RAND;;SHA1;{RAND;4;HEX}}", 3},
+		{"{RANDX} is not a tag, {RAND;5} is", 1},
+		{"{RAND;8;ABL} then unterminated {RAND;8", 1},
+		{"%7BRAND%3B8%3BHEX%7D and &lbrace;RAND&rbrace;", 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			if got := fastrand.CountTags([]byte(tc.template)); got != tc.expected {
+				t.Errorf("Expected %d tags, got %d", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("InputEncodingDisabled", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithInputEncoding(fastrand.RandomizerEncodingNone))
+		if got := engine.CountTags([]byte("%7BRAND%7D {RAND}")); got != 1 {
+			t.Errorf("Expected encoded tags to be ignored, got %d", got)
+		}
+	})
+}
+
+func TestContainsTags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		payload  string
+		expected bool
+	}{
+		{"Empty", "", false},
+		{"Plain", "no tags here", false},
+		{"Braces", "{\"json\": {}}", false},
+		{"Tag", "id={RAND;8;HEX}", true},
+		{"Nested", "{RAND;;SHA1;{RAND;4;HEX}}", true},
+		{"LookAlike", "{RANDX}", false},
+		{"Unterminated", "{RAND;8", false},
+		{"URLEncoded", "%7BRAND%3B8%3BHEX%7D", true},
+		{"LowercaseEscapes", "%7bRAND%7d", true},
+		{"HTMLEncoded", "&lbrace;RAND&rbrace;", true},
+		{"DoubleEncoded", "%257BRAND%257D", false},
+		{"EncodedLookAlike", "%7BRANDX%7D", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fastrand.ContainsTags([]byte(tc.payload)); got != tc.expected {
+				t.Errorf("Expected ContainsTags(%q) to be %v", tc.payload, tc.expected)
+			}
+			if got := fastrand.CountTags([]byte(tc.payload)) > 0; got != tc.expected {
+				t.Errorf("Expected ContainsTags to agree with CountTags for %q", tc.payload)
+			}
+		})
+	}
+
+	t.Run("InputEncoding", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithInputEncoding(fastrand.RandomizerEncodingHTML))
+		if engine.ContainsTags([]byte("%7BRAND%7D")) {
+			t.Error("Expected URL-encoded tags to be escaped when URL input is disabled")
+		}
+		if !engine.ContainsTags([]byte("&lbrace;RAND&rbrace;")) {
+			t.Error("Expected HTML-encoded tags to count when HTML input is enabled")
+		}
+	})
+}
+
+func TestKeywordToggleOrder(t *testing.T) {
+	generate := func(engine *fastrand.FastEngine) map[string]bool {
+		enabled := make(map[string]bool)
+		enabled["UUID"] = uuidRegex.MatchString(engine.RandomizerString("{RAND;UUID}"))
+		enabled["HEX"] = len(engine.RandomizerString("{RAND;4;HEX}")) == 8
+		enabled["IPV4"] = strings.Count(engine.RandomizerString("{RAND;IPV4}"), ".") == 3
+		return enabled
+	}
+
+	disable := fastrand.WithDisabledKeywords("UUID", "HEX", "IPV4")
+	enable := fastrand.WithEnabledKeywords("uuid")
+
+	disableFirst := generate(fastrand.NewEngine(disable, enable))
+	enableFirst := generate(fastrand.NewEngine(enable, disable))
+
+	expected := map[string]bool{"UUID": true, "HEX": false, "IPV4": false}
+	for kw, want := range expected {
+		if disableFirst[kw] != want {
+			t.Errorf("Disable then enable: expected %s enabled=%v, got %v", kw, want, disableFirst[kw])
+		}
+		if enableFirst[kw] != want {
+			t.Errorf("Enable then disable: expected %s enabled=%v, got %v", kw, want, enableFirst[kw])
+		}
+	}
+
+	t.Run("UnknownKeyword", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithEnabledKeywords("NOPE"), fastrand.WithKeywordChoices(true))
+		result := engine.RandomizerString("{RAND;NOPE,UUID}")
+		if !uuidRegex.MatchString(result) {
+			t.Errorf("Expected enabling an unknown keyword to have no effect, got %q", result)
+		}
+	})
+}
+
+func TestEngineDefaultsIsolation(t *testing.T) {
+	disable := fastrand.WithDisabledKeywords("UUID")
+	first := fastrand.NewEngine(
+		disable,
+		fastrand.WithCustomCharset("DIGIT", []byte("0")),
+		fastrand.WithKeywordAlias("ID", "HEX"),
+	)
+	second := fastrand.NewEngine(disable)
+	fresh := fastrand.NewEngine()
+
+	if uuidRegex.MatchString(first.RandomizerString("{RAND;UUID}")) {
+		t.Error("Expected UUID to be disabled on the configured engine")
+	}
+	if uuidRegex.MatchString(second.RandomizerString("{RAND;UUID}")) {
+		t.Error("Expected a reused option to disable UUID on a second engine")
+	}
+	if !uuidRegex.MatchString(fresh.RandomizerString("{RAND;UUID}")) {
+		t.Error("Expected disabling UUID on one engine not to leak into a fresh engine")
+	}
+	if !uuidRegex.MatchString(fastrand.RandomizerString("{RAND;UUID}")) {
+		t.Error("Expected disabling UUID on one engine not to leak into the default engine")
+	}
+
+	if got := first.RandomizerString("{RAND;8;DIGIT}"); got != "00000000" {
+		t.Errorf("Expected the custom DIGIT charset, got %q", got)
+	}
+	if got := second.RandomizerString("{RAND;64;DIGIT}"); strings.Trim(got, "0") == "" {
+		t.Errorf("Expected a custom charset not to leak into another engine, got %q", got)
+	}
+	if got := fresh.RandomizerString("{RAND;4;ID}"); len(got) == 8 {
+		t.Errorf("Expected an alias not to leak into another engine, got %q", got)
+	}
+
+	first.Reset()
+	if !uuidRegex.MatchString(first.RandomizerString("{RAND;UUID}")) {
+		t.Error("Expected Reset to restore the shared defaults")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = fastrand.NewEngine()
+	})
+	if allocs > 1 {
+		t.Errorf("Expected NewEngine without options to allocate at most once, got %v", allocs)
+	}
+}
+
+func TestWeightedList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "colors.txt")
+	content := "# color weight\nred 1\ngreen 3\n\nblue\nbroken weight\ntoo many fields 2\nzero 0\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := fastrand.NewEngine(fastrand.WithWeightedList("COLOR", path))
+	if err := engine.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	const samples = 50000
+	counts := make(map[string]int)
+	for i := 0; i < samples; i++ {
+		counts[engine.RandomizerString("{RAND;COLOR}")]++
+	}
+	if len(counts) != 3 {
+		t.Fatalf("Expected only red, green and blue, got %v", counts)
+	}
+	for color, expected := range map[string]float64{"red": 0.2, "green": 0.6, "blue": 0.2} {
+		if share := float64(counts[color]) / samples; share < expected-0.02 || share > expected+0.02 {
+			t.Errorf("Expected %s share near %.2f, got %.3f", color, expected, share)
+		}
+	}
+
+	t.Run("MissingFile", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithWeightedList("COLOR", filepath.Join(t.TempDir(), "missing.txt")))
+		if engine.Err() == nil {
+			t.Error("Expected an error for a missing file")
+		}
+	})
+}
+
+func TestFileList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("alpha  \n\n  beta\ngamma\t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := fastrand.NewEngine(fastrand.WithFileList("WORD", path))
+	if err := engine.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	seen := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		seen[engine.RandomizerString("{RAND;WORD}")]++
+	}
+	if len(seen) != 3 || seen["alpha"] == 0 || seen["beta"] == 0 || seen["gamma"] == 0 {
+		t.Errorf("Expected trimmed lines alpha, beta and gamma, got %v", seen)
+	}
+
+	t.Run("Cached", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte("changed\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if result := engine.RandomizerString("{RAND;WORD}"); result == "changed" {
+			t.Error("Expected the file to be read only once")
+		}
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		empty := filepath.Join(t.TempDir(), "empty.txt")
+		if err := os.WriteFile(empty, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		engine := fastrand.NewEngine(fastrand.WithFileList("WORD", empty))
+		if result := engine.RandomizerString("[{RAND;WORD}]"); result != "[]" {
+			t.Errorf("Expected an empty file to expand to nothing, got %q", result)
+		}
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithFileList("WORD", filepath.Join(t.TempDir(), "missing.txt")))
+		if engine.Err() == nil {
+			t.Error("Expected an error for a missing file")
+		}
+	})
+
+	t.Run("LongLine", func(t *testing.T) {
+		long := strings.Repeat("x", 2<<20)
+		path := filepath.Join(t.TempDir(), "long.txt")
+		if err := os.WriteFile(path, []byte(long+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		engine := fastrand.NewEngine(fastrand.WithFileList("WORD", path))
+		if result := engine.RandomizerString("{RAND;WORD}"); result != long {
+			t.Errorf("Expected the whole %d-byte line, got %d bytes", len(long), len(result))
+		}
+		if err := engine.Err(); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ReadError", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithFileList("WORD", t.TempDir()))
+		if err := engine.Err(); err != nil {
+			t.Fatalf("Expected the read error to wait for first use, got %v", err)
+		}
+		engine.RandomizerString("{RAND;WORD}")
+		if engine.Err() == nil {
+			t.Error("Expected the failed read to be reported")
+		}
+	})
+}
+
+func TestCSVOutputEncoding(t *testing.T) {
+	engine := fastrand.NewEngine(
+		fastrand.WithOutputEncoding(fastrand.RandomizerEncodingCSV),
+		fastrand.WithChoiceSet("TRICKY", []string{`a,b`, `say "hi"`, "line\r\nbreak", "plain"}),
+	)
+
+	var rows strings.Builder
+	for i := 0; i < 200; i++ {
+		rows.WriteString(engine.RandomizerString("{RAND;8;DIGIT},{RAND;20},{RAND;TRICKY}\n"))
+	}
+
+	records, err := csv.NewReader(strings.NewReader(rows.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got error %v for:\n%s", err, rows.String())
+	}
+	if len(records) != 200 {
+		t.Fatalf("Expected 200 records, got %d", len(records))
+	}
+	allowed := map[string]bool{`a,b`: true, `say "hi"`: true, "line\nbreak": true, "line\r\nbreak": true, "plain": true}
+	for _, record := range records {
+		if len(record) != 3 {
+			t.Fatalf("Expected 3 fields, got %d: %q", len(record), record)
+		}
+		if len(record[0]) != 8 || len(record[1]) != 20 {
+			t.Errorf("Unexpected field lengths in %q", record)
+		}
+		if !allowed[record[2]] {
+			t.Errorf("Unexpected round-tripped value %q", record[2])
+		}
+	}
+
+	if result := engine.RandomizerString("{RAND;;FILENAME;txt}"); strings.HasPrefix(result, `"`) {
+		t.Errorf("Expected fields without special characters to stay unquoted, got %q", result)
+	}
+}
+
+func TestBufferHint(t *testing.T) {
+	payload := []byte("head " + strings.Repeat("{RAND;99;BYTES}|", 10) + "{RAND;8;DIGIT} tail")
+	for _, hint := range []int{0, 1, 10 * 99, 1 << 16} {
+		engine := fastrand.NewEngine(fastrand.WithBufferHint(hint))
+		result := engine.Randomizer(payload)
+		if len(result) != len("head ")+10*100+8+len(" tail") {
+			t.Fatalf("Hint %d: unexpected output length %d", hint, len(result))
+		}
+		if !bytes.HasPrefix(result, []byte("head ")) || !bytes.HasSuffix(result, []byte(" tail")) {
+			t.Errorf("Hint %d: literal text was not preserved", hint)
+		}
+		for i := 0; i < 10; i++ {
+			if result[len("head ")+i*100+99] != '|' {
+				t.Fatalf("Hint %d: tag %d did not expand to 99 bytes", hint, i)
+			}
+		}
+	}
+}
+
+func TestBufferHintDefault(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithMaxLength(1e8))
+	payload := []byte(strings.Repeat("{RAND;8;HEX}", 10))
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	result := engine.Randomizer(payload)
+	runtime.ReadMemStats(&after)
+
+	if len(result) != 10*16 {
+		t.Fatalf("Unexpected output length %d", len(result))
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Expected short tags to reserve little under a large maxLength, allocated %d bytes", allocated)
+	}
+}
+
+func TestGlobalPrefixSuffix(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithGlobalPrefix("test_"), fastrand.WithGlobalSuffix("_end"))
+
+	t.Run("Placement", func(t *testing.T) {
+		result := engine.RandomizerString("{RAND;8;DIGIT}")
+		if !regexp.MustCompile(`^test_[0-9]{8}_end$`).MatchString(result) {
+			t.Errorf("Unexpected result %q", result)
+		}
+	})
+
+	t.Run("EmptyTemplate", func(t *testing.T) {
+		if result := engine.RandomizerString(""); result != "test__end" {
+			t.Errorf("Expected the wrappers alone, got %q", result)
+		}
+	})
+
+	t.Run("NotEncoded", func(t *testing.T) {
+		htmlEngine := fastrand.NewEngine(
+			fastrand.WithOutputEncoding(fastrand.RandomizerEncodingHTML),
+			fastrand.WithGlobalPrefix("<b>"),
+			fastrand.WithGlobalSuffix("</b>"),
+		)
+		if result := htmlEngine.RandomizerString("a&b"); result != "<b>a&amp;b</b>" {
+			t.Errorf("Expected only the template to be encoded, got %q", result)
+		}
+	})
+
+	t.Run("Writers", func(t *testing.T) {
+		var to, stream bytes.Buffer
+		if _, err := engine.RandomizerTo(&to, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.RandomizerStream(strings.NewReader("x"), &stream); err != nil {
+			t.Fatal(err)
+		}
+		if to.String() != "test_x_end" || stream.String() != "test_x_end" {
+			t.Errorf("Expected wrapped output, got %q and %q", to.String(), stream.String())
+		}
+	})
+}
+
+func TestWithoutEmbeddedProviders(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithoutEmbeddedProviders())
+	for i := 0; i < 50; i++ {
+		result := engine.RandomizerString("{RAND;8;EMAIL}")
+		if !regexp.MustCompile(`^[a-z]{8}@example\.com$`).MatchString(result) {
+			t.Fatalf("Expected the example.com fallback, got %q", result)
+		}
+	}
+
+	custom := fastrand.NewEngine(fastrand.WithoutEmbeddedProviders(), fastrand.WithMailProviders([]string{"corp.test"}))
+	if result := custom.RandomizerString("{RAND;;EMAIL}"); !strings.HasSuffix(result, "@corp.test") {
+		t.Errorf("Expected the caller-provided provider, got %q", result)
+	}
+}
+
+func TestInvertedRanges(t *testing.T) {
+	t.Run("SwappedByDefault", func(t *testing.T) {
+		seen := map[int]bool{}
+		for i := 0; i < 500; i++ {
+			n := len(fastrand.RandomizerString("{RAND;10-5;ABL}"))
+			if n < 5 || n > 10 {
+				t.Fatalf("Expected a length in [5, 10], got %d", n)
+			}
+			seen[n] = true
+		}
+		if len(seen) != 6 {
+			t.Errorf("Expected every length in [5, 10], saw %v", seen)
+		}
+		if err := fastrand.Validate([]byte("{RAND;10-5;ABL}")); err != nil {
+			t.Errorf("Expected swapped ranges to validate, got %v", err)
+		}
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithStrictRanges())
+		if result := engine.RandomizerString("a{RAND;10-5;ABL}b"); result != "a{RAND;10-5;ABL}b" {
+			t.Errorf("Expected the inverted range to stay literal, got %q", result)
+		}
+		if n := len(engine.RandomizerString("{RAND;5-10;ABL}")); n < 5 || n > 10 {
+			t.Errorf("Expected ascending ranges to still work, got length %d", n)
+		}
+		var tmplErr *fastrand.TemplateError
+		if err := engine.Validate([]byte("{RAND;10-5;ABL}")); !errors.As(err, &tmplErr) || tmplErr.Kind != fastrand.BadRange {
+			t.Errorf("Expected a BadRange error, got %v", err)
+		}
+	})
+}
+
+func TestExpandAll(t *testing.T) {
+	for _, n := range []int{-1, 0} {
+		if results := fastrand.ExpandAll([]byte("{RAND}"), n); results == nil || len(results) != 0 {
+			t.Errorf("n=%d: expected an empty slice, got %v", n, results)
+		}
+	}
+
+	results := fastrand.ExpandAll([]byte("tok_{RAND;32;HEX}"), 100)
+	if len(results) != 100 {
+		t.Fatalf("Expected 100 results, got %d", len(results))
+	}
+	seen := map[string]bool{}
+	for _, r := range results {
+		if !regexp.MustCompile(`^tok_[0-9a-f]{64}$`).MatchString(r) {
+			t.Fatalf("Unexpected result %q", r)
+		}
+		seen[r] = true
+	}
+	if len(seen) != 100 {
+		t.Errorf("Expected 100 distinct results, got %d", len(seen))
+	}
+
+	for _, r := range fastrand.ExpandAll([]byte("no tags here"), 5) {
+		if r != "no tags here" {
+			t.Errorf("Expected the literal, got %q", r)
+		}
+	}
+}
+
+func TestCustomKeywordPrecedence(t *testing.T) {
+	t.Run("CustomGeneratorOverridesBuiltIn", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithCustomKeyword("hex", func(length int) []byte {
+			return bytes.Repeat([]byte("G"), length)
+		}))
+		if result := engine.RandomizerString("{RAND;4;HEX}"); result != "GGGG" {
+			t.Errorf("Expected the custom HEX generator to win, got %q", result)
+		}
+		if result := engine.RandomizerString("{RAND;4;ABL,HEX}"); result != "GGGG" && !regexp.MustCompile(`^[a-z]{4}$`).MatchString(result) {
+			t.Errorf("Expected keyword choices to use the custom HEX too, got %q", result)
+		}
+	})
+
+	t.Run("CustomCharsetOverridesBuiltIn", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithCustomCharset("HEX", []byte("xy")))
+		result := engine.RandomizerString("{RAND;12;HEX}")
+		if !regexp.MustCompile(`^[xy]{12}$`).MatchString(result) {
+			t.Errorf("Expected the custom HEX charset to win, got %q", result)
+		}
+	})
+
+	t.Run("CharsetDefinesNewKeyword", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithCustomCharset("VOWEL", []byte("aeiou")))
+		result := engine.RandomizerString("{RAND;10;VOWEL}")
+		if !regexp.MustCompile(`^[aeiou]{10}$`).MatchString(result) {
+			t.Errorf("Expected vowels, got %q", result)
+		}
+		if err := engine.Validate([]byte("{RAND;10;VOWEL}")); err != nil {
+			t.Errorf("Expected charset keywords to validate, got %v", err)
+		}
+	})
+
+	t.Run("GeneratorOverridesCharset", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithCustomCharset("HEX", []byte("xy")),
+			fastrand.WithCustomKeyword("HEX", func(int) []byte { return []byte("gen") }),
+		)
+		if result := engine.RandomizerString("{RAND;12;HEX}"); result != "gen" {
+			t.Errorf("Expected the custom generator to beat the custom charset, got %q", result)
+		}
+	})
+
+	t.Run("OverridesDisabled", func(t *testing.T) {
+		engine := fastrand.NewEngine(
+			fastrand.WithDisabledKeywords("HEX"),
+			fastrand.WithCustomKeyword("HEX", func(int) []byte { return []byte("gen") }),
+		)
+		if result := engine.RandomizerString("{RAND;;HEX}"); result != "gen" {
+			t.Errorf("Expected the custom generator despite the built-in being disabled, got %q", result)
+		}
+	})
+}
+
+func TestInputDecodingToggle(t *testing.T) {
+	const literal = "q=%7BRAND;8;DIGIT%7D&amp;x=&lbrace;RAND;8;DIGIT&rbrace;"
+
+	t.Run("Disabled", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithInputEncoding(fastrand.RandomizerEncodingNone))
+		if result := engine.RandomizerString(literal); result != literal {
+			t.Errorf("Expected encoded tags to be preserved, got %q", result)
+		}
+		if err := engine.Validate([]byte(literal)); err != nil {
+			t.Errorf("Expected no tags to validate, got %v", err)
+		}
+	})
+
+	t.Run("HTMLOnly", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithInputEncoding(fastrand.RandomizerEncodingHTML))
+		result := engine.RandomizerString(literal)
+		if !regexp.MustCompile(`^q=%7BRAND;8;DIGIT%7D&amp;x=[0-9]{8}$`).MatchString(result) {
+			t.Errorf("Expected only the HTML-encoded tag to expand, got %q", result)
+		}
+	})
+
+	t.Run("URLOnly", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithInputEncoding(fastrand.RandomizerEncodingURL))
+		result := engine.RandomizerString(literal)
+		if !regexp.MustCompile(`^q=[0-9]{8}&amp;x=&lbrace;RAND;8;DIGIT&rbrace;$`).MatchString(result) {
+			t.Errorf("Expected only the URL-encoded tag to expand, got %q", result)
+		}
+	})
+
+	t.Run("PackageDefault", func(t *testing.T) {
+		result := fastrand.RandomizerString("%7BRAND;8;DIGIT%7D")
+		if !regexp.MustCompile(`^[0-9]{8}$`).MatchString(result) {
+			t.Errorf("Expected package functions to decode URL tags by default, got %q", result)
+		}
+	})
+}
+
+func TestDefaultCharset(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithDefaultCharset(fastrand.CharsAlphabetDigits))
+	alphanumeric := regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+
+	testCases := []struct {
+		template string
+		length   int
+	}{
+		{"{RANDOM}", 16},
+		{"{RAND;32}", 32},
+		{"{RAND;32;}", 32},
+		{"{RAND;32;NOPE}", 32},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				result := engine.RandomizerString(tc.template)
+				if len(result) != tc.length || !alphanumeric.MatchString(result) {
+					t.Fatalf("Expected %d alphanumerics, got %q", tc.length, result)
+				}
+			}
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithDefaultCharset([]byte("x")), fastrand.WithDisabledKeywords("UUID"))
+		if result := engine.RandomizerString("{RAND;4;UUID}"); result != "xxxx" {
+			t.Errorf("Expected the disabled keyword to use the default charset, got %q", result)
+		}
+	})
+
+	t.Run("EmptyRejected", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithDefaultCharset(nil))
+		if engine.Err() == nil {
+			t.Error("Expected an empty default charset to be rejected")
+		}
+		if result := engine.RandomizerString("{RAND;8}"); len(result) != 8 {
+			t.Errorf("Expected CharsAll to stay in place, got %q", result)
+		}
+	})
+}
+
+func TestKeywordLengthBounds(t *testing.T) {
+	engine := fastrand.NewEngine(
+		fastrand.WithKeywordLengthBounds("email", 3, 10),
+		fastrand.WithKeywordLengthBounds("BYTES", 1, 4096),
+	)
+	if err := engine.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("SameTemplate", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			result := engine.Randomizer([]byte("{RAND;20;EMAIL}|{RAND;4096;BYTES}"))
+			local, _, found := bytes.Cut(result, []byte("@"))
+			if !found || len(local) > 10 || len(local) < 3 {
+				t.Fatalf("Expected the EMAIL local part clamped to 3-10, got %q", local)
+			}
+			if _, data, _ := bytes.Cut(result, []byte("|")); len(data) != 4096 {
+				t.Fatalf("Expected 4096 bytes beyond the global maximum, got %d", len(data))
+			}
+		}
+	})
+
+	t.Run("Ranges", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			if n := len(engine.Randomizer([]byte("{RAND;1000-2000;BYTES}"))); n < 1000 || n > 2000 {
+				t.Fatalf("Expected a length within the range, got %d", n)
+			}
+		}
+		if err := engine.Validate([]byte("{RAND;1000-2000;BYTES}")); err != nil {
+			t.Errorf("Expected the range to validate against the keyword bounds, got %v", err)
+		}
+		if err := engine.Validate([]byte("{RAND;1000-2000;HEX}")); err == nil {
+			t.Error("Expected the range to exceed the global bounds of HEX")
+		}
+	})
+
+	t.Run("GlobalFallback", func(t *testing.T) {
+		if result := engine.RandomizerString("{RAND;200;DIGIT}"); len(result) != 16 {
+			t.Errorf("Expected keywords without bounds to use the global ones, got %d digits", len(result))
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		for _, bounds := range [][2]int{{10, 3}, {0, 5}} {
+			if fastrand.NewEngine(fastrand.WithKeywordLengthBounds("EMAIL", bounds[0], bounds[1])).Err() == nil {
+				t.Errorf("Expected bounds %v to be rejected", bounds)
+			}
+		}
+	})
+}
+
+func TestMixedInputEncodings(t *testing.T) {
+	const payload = "a=%7BRAND;4;DIGIT%7D&b=&lbrace;RAND;4;DIGIT&rbrace;&c=%7brand%3b4%3bDIGIT%7d" +
+		"&d=%7BRAND&semi;4%3BDIGIT&rbrace;&e=&lbrace;RAND%3b4&semi;DIGIT%7D"
+	testCases := []struct {
+		name     string
+		encoding fastrand.RandomizerEncoding
+		pattern  string
+	}{
+		{"Both", fastrand.RandomizerEncodingURL | fastrand.RandomizerEncodingHTML,
+			`^a=\d{4}&b=\d{4}&c=%7brand;4;DIGIT}&d=\d{4}&e=\d{4}$`},
+		{"URLOnly", fastrand.RandomizerEncodingURL,
+			`^a=\d{4}&b=&lbrace;RAND;4;DIGIT&rbrace;&c=%7brand;4;DIGIT}&d=\{RAND&semi;4;DIGIT&rbrace;&e=&lbrace;RAND;4&semi;DIGIT}$`},
+		{"HTMLOnly", fastrand.RandomizerEncodingHTML,
+			`^a=%7BRAND;4;DIGIT%7D&b=\d{4}&c=%7brand%3b4%3bDIGIT%7d&d=%7BRAND;4%3BDIGIT}&e=\{RAND%3b4;DIGIT%7D$`},
+		{"None", fastrand.RandomizerEncodingNone, `^` + regexp.QuoteMeta(payload) + `$`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := fastrand.NewEngine(fastrand.WithInputEncoding(tc.encoding))
+			result := engine.RandomizerString(payload)
+			if !regexp.MustCompile(tc.pattern).MatchString(result) {
+				t.Errorf("Unexpected expansion:\n got: %s\nwant: %s", result, tc.pattern)
+			}
+		})
+	}
+
+	t.Run("LowercaseEscapes", func(t *testing.T) {
+		result := fastrand.RandomizerString("%7bRAND%3b4%3bDIGIT%7d")
+		if !regexp.MustCompile(`^\d{4}$`).MatchString(result) {
+			t.Errorf("Expected lowercase hex escapes to be decoded, got %q", result)
+		}
+	})
+}
+
+func TestChoiceSelection(t *testing.T) {
+	t.Run("Keywords", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i := 0; i < 500; i++ {
+			result := fastrand.RandomizerString("{RAND;6;ABL,NOPE,DIGIT,,ABU}")
+			switch {
+			case regexp.MustCompile(`^[a-z]{6}$`).MatchString(result):
+				seen["ABL"] = true
+			case regexp.MustCompile(`^[0-9]{6}$`).MatchString(result):
+				seen["DIGIT"] = true
+			case regexp.MustCompile(`^[A-Z]{6}$`).MatchString(result):
+				seen["ABU"] = true
+			default:
+				t.Fatalf("Expected a value from a known choice, got %q", result)
+			}
+		}
+		if len(seen) != 3 {
+			t.Errorf("Expected every known choice to be picked, got %v", seen)
+		}
+	})
+
+	t.Run("Lengths", func(t *testing.T) {
+		seen := make(map[int]bool)
+		for i := 0; i < 500; i++ {
+			result := fastrand.RandomizerString("{RAND;3,x,7,500,12;DIGIT}")
+			switch len(result) {
+			case 3, 7, 12:
+				seen[len(result)] = true
+			default:
+				t.Fatalf("Expected a length of 3, 7 or 12, got %d", len(result))
+			}
+		}
+		if len(seen) != 3 {
+			t.Errorf("Expected every valid length to be picked, got %v", seen)
+		}
+	})
+}
+
+func TestRandomizerDocs(t *testing.T) {
+	t.Run("ThreeDocs", func(t *testing.T) {
+		docs := fastrand.RandomizerDocs([]byte("id={RAND;;ONCE;id;8;HEX}{RAND;;SPLIT}id={RAND;;ONCE;id;8;HEX}{RANDOM;SPLIT}plain"))
+		if len(docs) != 3 {
+			t.Fatalf("Expected 3 documents, got %d: %q", len(docs), docs)
+		}
+		pattern := regexp.MustCompile(`^id=[0-9a-f]{16}$`)
+		if !pattern.Match(docs[0]) || !pattern.Match(docs[1]) || string(docs[2]) != "plain" {
+			t.Fatalf("Unexpected documents %q", docs)
+		}
+		if bytes.Equal(docs[0], docs[1]) {
+			t.Errorf("Expected each document to be expanded independently, got %q twice", docs[0])
+		}
+	})
+
+	t.Run("EmptyDocs", func(t *testing.T) {
+		template := []byte("{RAND;;SPLIT}a{RAND;;SPLIT}{RAND;;SPLIT}b{RAND;;SPLIT}")
+		if docs := fastrand.RandomizerDocs(template); len(docs) != 5 || string(docs[1]) != "a" || string(docs[3]) != "b" {
+			t.Errorf("Expected empty documents around the markers, got %q", docs)
+		}
+		engine := fastrand.NewEngine(fastrand.WithDropEmptyDocs())
+		if docs := engine.RandomizerDocs(template); len(docs) != 2 || string(docs[0]) != "a" || string(docs[1]) != "b" {
+			t.Errorf("Expected empty documents to be dropped, got %q", docs)
+		}
+	})
+
+	t.Run("NestedAndEncoded", func(t *testing.T) {
+		docs := fastrand.RandomizerDocs([]byte("a{RAND;;SHA1;{RAND;;SPLIT}}%7BRAND;;SPLIT%7Db"))
+		if len(docs) != 2 || len(docs[0]) != 41 || string(docs[1]) != "b" {
+			t.Errorf("Expected only top-level markers to split, got %q", docs)
+		}
+	})
+
+	t.Run("RandomizerIgnoresMarker", func(t *testing.T) {
+		if result := fastrand.RandomizerString("a{RAND;;SPLIT}b"); result != "ab" {
+			t.Errorf("Expected the marker to expand to nothing, got %q", result)
+		}
+	})
+}
+
+func TestRepeatingKeywordsRespectMaxLength(t *testing.T) {
+	engines := map[string]*fastrand.FastEngine{
+		"MaxAboveDefault": fastrand.NewEngine(fastrand.WithMaxLength(20)),
+		"MaxBelowDefault": fastrand.NewEngine(fastrand.WithMaxLength(8)),
+	}
+	templates := []string{
+		"{RAND;99;SPACE}", "{RAND;5-99;NULL}", "{RAND;1k;SPACE}", "{RAND;2m;NULL}",
+		"{RAND;10,99;SPACE}", "{RAND;999999999;NULL}", "{RAND;SPACE}", "{RAND;;NULL}", "{RAND}",
+	}
+	for name, engine := range engines {
+		maxLength := 20
+		if name == "MaxBelowDefault" {
+			maxLength = 8
+		}
+		for _, template := range templates {
+			t.Run(name+template, func(t *testing.T) {
+				for i := 0; i < 20; i++ {
+					if n := len(engine.RandomizerString(template)); n > maxLength {
+						t.Fatalf("Expected at most %d characters, got %d", maxLength, n)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithCollapseWhitespace())
+
+	testCases := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"Literal", "a \t\n b", "a b"},
+		{"Tag", "a{RAND;5;SPACE}b", "a b"},
+		{"AcrossSegments", "a  {RAND;5;SPACE}\r\n{RAND;3;SPACE} b", "a b"},
+		{"NullBytes", "\x00\x00  x\x00 \x00\t\n\x00", "\x00\x00 x\x00 \x00 \x00"},
+		{"Unicode", "a  b", "a  b"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := engine.RandomizerString(tc.template); result != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, result)
+			}
+		})
+	}
+
+	t.Run("NestedArgument", func(t *testing.T) {
+		want := fastrand.RandomizerString("{RAND;;SHA256;a  b}")
+		if result := engine.RandomizerString("{RAND;;SHA256;a{RAND;2;SPACE}b}"); result != want {
+			t.Errorf("Expected nested arguments to keep their whitespace, got %q", result)
+		}
+	})
+
+	t.Run("OutputEncoding", func(t *testing.T) {
+		encoded := fastrand.NewEngine(fastrand.WithCollapseWhitespace(), fastrand.WithOutputEncoding(fastrand.RandomizerEncodingURL))
+		if result := encoded.RandomizerString("a \n {RAND;4;SPACE}b"); result != "a+b" {
+			t.Errorf("Expected whitespace collapsed before encoding, got %q", result)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		var out strings.Builder
+		template := strings.Repeat("x   ", 20000)
+		if err := engine.RandomizerStream(strings.NewReader(template), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := strings.Repeat("x ", 20000); out.String() != want {
+			t.Errorf("Expected a collapsed stream of %d bytes, got %d bytes", len(want), out.Len())
+		}
+	})
+}
+
+func TestRememberRecall(t *testing.T) {
+	t.Run("AcrossCalls", func(t *testing.T) {
+		engine := fastrand.NewEngine()
+		if result := engine.RandomizerString("[{RAND;;RECALL;users}]"); result != "[]" {
+			t.Fatalf("Expected an empty pool to recall nothing, got %q", result)
+		}
+		remembered := make(map[string]bool)
+		for i := 0; i < 5; i++ {
+			value := engine.RandomizerString("{RAND;;REMEMBER;users;12;ABL}")
+			if len(value) != 12 {
+				t.Fatalf("Expected REMEMBER to emit its value, got %q", value)
+			}
+			remembered[value] = true
+		}
+		recalled := make(map[string]bool)
+		for i := 0; i < 200; i++ {
+			value := engine.RandomizerString("{RAND;;RECALL;users}")
+			if !remembered[value] {
+				t.Fatalf("Expected a remembered value, got %q", value)
+			}
+			recalled[value] = true
+		}
+		if len(recalled) != len(remembered) {
+			t.Errorf("Expected every remembered value to be recalled, got %d of %d", len(recalled), len(remembered))
+		}
+		if result := engine.RandomizerString("{RAND;;RECALL;orders}"); result != "" {
+			t.Errorf("Expected another pool to be empty, got %q", result)
+		}
+		if result := fastrand.NewEngine().RandomizerString("{RAND;;RECALL;users}"); result != "" {
+			t.Errorf("Expected pools to belong to their engine, got %q", result)
+		}
+	})
+
+	t.Run("SameCall", func(t *testing.T) {
+		result := fastrand.NewEngine().RandomizerString("{RAND;;REMEMBER;id;8;HEX}={RAND;;RECALL;id}")
+		if left, right, _ := strings.Cut(result, "="); left != right {
+			t.Errorf("Expected the value remembered earlier in the call to be recalled, got %q", result)
+		}
+	})
+
+	t.Run("Capacity", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithPoolCapacity(2))
+		var values []string
+		for i := 0; i < 5; i++ {
+			values = append(values, engine.RandomizerString("{RAND;;REMEMBER;p;16;HEX}"))
+		}
+		for i := 0; i < 100; i++ {
+			if value := engine.RandomizerString("{RAND;;RECALL;p}"); !slices.Contains(values[3:], value) {
+				t.Fatalf("Expected only the latest two values, got %q of %q", value, values)
+			}
+		}
+		if err := fastrand.NewEngine(fastrand.WithPoolCapacity(0)).Err(); err == nil {
+			t.Error("Expected a zero pool capacity to be reported")
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		// The seeded source is locked, unlike the shared fast source.
+		engine := fastrand.NewEngine(fastrand.WithPoolCapacity(8), fastrand.WithSeed(1))
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					result := engine.RandomizerString("{RAND;;REMEMBER;p;6;DIGIT}{RAND;;RECALL;p}")
+					if len(result) != 12 {
+						t.Errorf("Expected a remembered and a recalled value, got %q", result)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("ResetState", func(t *testing.T) {
+		engine := fastrand.NewEngine()
+		engine.RandomizerString("{RAND;;REMEMBER;p;4;DIGIT}")
+		engine.ResetState()
+		if result := engine.RandomizerString("{RAND;;RECALL;p}"); result != "" {
+			t.Errorf("Expected ResetState to empty the pools, got %q", result)
+		}
+	})
+}
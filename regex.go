@@ -0,0 +1,137 @@
+package fastrand
+
+import (
+	"regexp"
+	"strconv"
+)
+
+const (
+	// maxRegexDepth bounds how deeply REGEX nests groups.
+	maxRegexDepth = 3
+	// maxRegexRepeat bounds the counts of {m} and {m,n} quantifiers.
+	maxRegexRepeat = 9
+	// regexAttempts is how often REGEX regenerates an expression that does
+	// not compile before settling for a single literal.
+	regexAttempts = 8
+)
+
+var (
+	regexLiterals   = CharsList("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-@#%=:,")
+	regexMetaChars  = CharsList(`.*+?()[]{}|^$\`)
+	regexEscapes    = []string{`\d`, `\D`, `\w`, `\W`, `\s`, `\S`}
+	regexClassParts = []string{"a-z", "A-Z", "0-9", "a-f", "A-F", "_", `\-`, ".", " ", `\d`, `\s`, `\w`}
+)
+
+// regexBuilder generates an expression whose quantifiers never nest.
+type regexBuilder struct {
+	r      Source
+	out    []byte
+	budget int
+}
+
+// generateRegex emits an expression of about length atoms.
+func generateRegex(r Source, length int) []byte {
+	for attempt := 0; attempt < regexAttempts; attempt++ {
+		b := regexBuilder{r: r, budget: max(length, 1)}
+		if randBool(r) {
+			b.out = append(b.out, '^')
+		}
+		b.alternation(0)
+		if randBool(r) {
+			b.out = append(b.out, '$')
+		}
+		if _, err := regexp.Compile(string(b.out)); err == nil {
+			return b.out
+		}
+	}
+	return []byte{pick(r, regexLiterals)}
+}
+
+func (b *regexBuilder) alternation(depth int) {
+	branches := 1
+	if b.budget > 2 && intN(b.r, 3) == 0 {
+		branches = intRange(b.r, 2, 3)
+	}
+	share := max(b.budget/branches, 1)
+	for i := 0; i < branches; i++ {
+		if i > 0 {
+			b.out = append(b.out, '|')
+		}
+		b.sequence(depth, share)
+	}
+}
+
+func (b *regexBuilder) sequence(depth, atoms int) {
+	for n := 0; n == 0 || n < atoms && b.budget > 0; n++ {
+		if depth < maxRegexDepth && b.budget > 2 && intN(b.r, 5) == 0 {
+			b.group(depth + 1)
+			continue
+		}
+		b.atom()
+		b.quantifier()
+	}
+}
+
+func (b *regexBuilder) group(depth int) {
+	if randBool(b.r) {
+		b.out = append(b.out, "(?:"...)
+	} else {
+		b.out = append(b.out, '(')
+	}
+	b.alternation(depth)
+	b.out = append(b.out, ')')
+}
+
+func (b *regexBuilder) atom() {
+	b.budget--
+	switch intN(b.r, 10) {
+	case 0:
+		b.out = append(b.out, '.')
+	case 1:
+		b.out = append(b.out, '\\', pick(b.r, regexMetaChars))
+	case 2:
+		b.out = append(b.out, pick(b.r, regexEscapes)...)
+	case 3, 4:
+		b.class()
+	default:
+		b.out = append(b.out, pick(b.r, regexLiterals))
+	}
+}
+
+func (b *regexBuilder) class() {
+	b.out = append(b.out, '[')
+	if intN(b.r, 4) == 0 {
+		b.out = append(b.out, '^')
+	}
+	for n := intRange(b.r, 1, 3); n > 0; n-- {
+		b.out = append(b.out, pick(b.r, regexClassParts)...)
+	}
+	b.out = append(b.out, ']')
+}
+
+func (b *regexBuilder) quantifier() {
+	switch intN(b.r, 8) {
+	case 0:
+		b.out = append(b.out, '*')
+	case 1:
+		b.out = append(b.out, '+')
+	case 2:
+		b.out = append(b.out, '?')
+	case 3:
+		b.out = append(b.out, '{')
+		b.out = strconv.AppendInt(b.out, int64(intRange(b.r, 1, maxRegexRepeat)), 10)
+		b.out = append(b.out, '}')
+	case 4:
+		low := intN(b.r, maxRegexRepeat)
+		b.out = append(b.out, '{')
+		b.out = strconv.AppendInt(b.out, int64(low), 10)
+		b.out = append(b.out, ',')
+		b.out = strconv.AppendInt(b.out, int64(intRange(b.r, max(low, 1), maxRegexRepeat)), 10)
+		b.out = append(b.out, '}')
+	default:
+		return
+	}
+	if intN(b.r, 4) == 0 {
+		b.out = append(b.out, '?')
+	}
+}
@@ -0,0 +1,57 @@
+package fastrand_test
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+// hasNestedRepeat reports whether re repeats an expression that itself
+// contains a repetition, such as (a+)+.
+func hasNestedRepeat(re *syntax.Regexp, inRepeat bool) bool {
+	repeat := re.Op == syntax.OpStar || re.Op == syntax.OpPlus || re.Op == syntax.OpQuest || re.Op == syntax.OpRepeat
+	if repeat && inRepeat {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasNestedRepeat(sub, inRepeat || repeat) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegexKeyword(t *testing.T) {
+	for _, length := range []int{1, 2, 5, 16, 40, 99} {
+		t.Run(fmt.Sprint(length), func(t *testing.T) {
+			template := fmt.Sprintf("{RAND;%d;REGEX}", length)
+			for i := 0; i < 300; i++ {
+				expr := fastrand.RandomizerString(template)
+				if _, err := regexp.Compile(expr); err != nil {
+					t.Fatalf("Expected %q to compile: %v", expr, err)
+				}
+				parsed, err := syntax.Parse(expr, syntax.Perl)
+				if err != nil {
+					t.Fatalf("Expected %q to parse: %v", expr, err)
+				}
+				if hasNestedRepeat(parsed, false) {
+					t.Fatalf("Expected no nested quantifiers in %q", expr)
+				}
+			}
+		})
+	}
+
+	t.Run("Complexity", func(t *testing.T) {
+		short, long := 0, 0
+		for i := 0; i < 100; i++ {
+			short += len(fastrand.RandomizerString("{RAND;2;REGEX}"))
+			long += len(fastrand.RandomizerString("{RAND;40;REGEX}"))
+		}
+		if long <= 4*short {
+			t.Errorf("Expected the length to scale the expression, got %d and %d bytes", short, long)
+		}
+	})
+}
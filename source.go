@@ -0,0 +1,200 @@
+package fastrand
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+// Source is where an engine draws its randomness from. Intn must return a
+// uniform value in [0, n) and Read must fill p completely. FastSource and
+// SecureSource are the built-in PCG and ChaCha8 sources; a scripted Source
+// makes an engine's output fully deterministic.
+type Source interface {
+	Intn(n int) int
+	Read(p []byte) (int, error)
+}
+
+var (
+	FastSource   Source = fastSource{}
+	SecureSource Source = secureSource{}
+)
+
+type fastSource struct{}
+
+func (fastSource) Intn(n int) int { return pcgSrc.IntN(n) }
+
+func (fastSource) Read(p []byte) (int, error) { return FastReader.Read(p) }
+
+type secureSource struct{}
+
+func (secureSource) Intn(n int) int { return chaChaSrc.IntN(n) }
+
+func (secureSource) Read(p []byte) (int, error) { return SecureReader.Read(p) }
+
+// seededSource is a mutex-guarded PCG source with a fixed seed.
+type seededSource struct {
+	mu     sync.Mutex
+	seed   uint64
+	stream uint64
+	rng    *rand.Rand
+	reader randReader
+}
+
+func newSeededSource(seed uint64) *seededSource {
+	s := &seededSource{seed: seed, stream: seed}
+	s.reset()
+	return s
+}
+
+// newIndexedSource returns the source of the index-th expansion for seed.
+func newIndexedSource(seed, index uint64) *seededSource {
+	s := &seededSource{seed: seed, stream: splitmix64(seed ^ splitmix64(index))}
+	s.reset()
+	return s
+}
+
+// splitmix64 is the SplitMix64 output function, a cheap bijective mixer.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// reset starts the sequence over from the seed.
+func (s *seededSource) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pcg := rand.NewPCG(s.seed, s.stream)
+	s.rng, s.reader = rand.New(pcg), randReader{src: pcg}
+}
+
+func (s *seededSource) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.IntN(n)
+}
+
+func (s *seededSource) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reader.Read(p)
+}
+
+// lockedSource guards a private generator with a mutex.
+type lockedSource struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	reader randReader
+}
+
+func newLockedSource(src rand.Source) *lockedSource {
+	return &lockedSource{rng: rand.New(src), reader: randReader{src: src}}
+}
+
+func (s *lockedSource) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.IntN(n)
+}
+
+func (s *lockedSource) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reader.Read(p)
+}
+
+// concurrentSource returns src, or a locked copy if it is not concurrency safe.
+func concurrentSource(src Source) Source {
+	var seed [32]byte
+	switch src.(type) {
+	case fastSource:
+		_, _ = crand.Read(seed[:16])
+		return newLockedSource(rand.NewPCG(binary.LittleEndian.Uint64(seed[:8]), binary.LittleEndian.Uint64(seed[8:16])))
+	case secureSource:
+		_, _ = crand.Read(seed[:])
+		return newLockedSource(rand.NewChaCha8(seed))
+	}
+	return src
+}
+
+// The helpers below mirror the package-level generators for an arbitrary
+// Source and are what the engine's keywords use.
+
+func intN(r Source, n int) int {
+	if n <= 0 {
+		panic("fastrand: argument n must be positive")
+	}
+	return r.Intn(n)
+}
+
+func intRange(r Source, min, max int) int {
+	if min > max {
+		panic(fmt.Sprintf("fastrand: invalid integer range [%d, %d]", min, max))
+	}
+	if min == max {
+		return min
+	}
+	return min + r.Intn(max-min+1)
+}
+
+func randBool(r Source) bool {
+	return r.Intn(2) == 1
+}
+
+func randFloat64(r Source) float64 {
+	var b [8]byte
+	readFull(r, b[:])
+	return float64(binary.LittleEndian.Uint64(b[:])>>11) / (1 << 53)
+}
+
+// randNormFloat64 returns a standard normal sample.
+func randNormFloat64(r Source) float64 {
+	u := 1 - randFloat64(r)
+	return math.Sqrt(-2*math.Log(u)) * math.Cos(2*math.Pi*randFloat64(r))
+}
+
+func randBytes(r Source, length int) []byte {
+	b := make([]byte, length)
+	readFull(r, b)
+	return b
+}
+
+func readFull(r Source, b []byte) {
+	if _, err := r.Read(b); err != nil {
+		panic(fmt.Sprintf("fastrand: failed to read random bytes: %v", err))
+	}
+}
+
+func randString(r Source, length int, charset CharsList) string {
+	b := appendRandString(r, make([]byte, 0, max(length, 0)), length, charset)
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// appendRandString is randString appending to dst.
+func appendRandString(r Source, dst []byte, length int, charset CharsList) []byte {
+	if length <= 0 {
+		panic("fastrand: length must be positive")
+	}
+	if len(charset) == 0 {
+		panic("fastrand: charset must not be empty")
+	}
+	dst = slices.Grow(dst, length)
+	for i := 0; i < length; i++ {
+		dst = append(dst, charset[r.Intn(len(charset))])
+	}
+	return dst
+}
+
+func pick[T any](r Source, items []T) T {
+	if len(items) == 0 {
+		panic("fastrand: cannot choose from an empty slice")
+	}
+	return items[r.Intn(len(items))]
+}
@@ -0,0 +1,186 @@
+package fastrand_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+// scriptedSource returns its values in order (modulo n) and fills reads with
+// fill, so an engine using it is fully deterministic.
+type scriptedSource struct {
+	values []int
+	next   int
+	fill   byte
+}
+
+func (s *scriptedSource) Intn(n int) int {
+	if len(s.values) == 0 {
+		return 0
+	}
+	v := s.values[s.next%len(s.values)]
+	s.next++
+	return v % n
+}
+
+func (s *scriptedSource) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = s.fill
+	}
+	return len(p), nil
+}
+
+func TestWithSource(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   *scriptedSource
+		template string
+		want     string
+	}{
+		{"Digits", &scriptedSource{values: []int{1, 2, 3, 4}}, "{RAND;4;DIGIT}", "1234"},
+		{"LastLetter", &scriptedSource{values: []int{25}}, "{RAND;3;ABL}", "zzz"},
+		{"Bytes", &scriptedSource{fill: 0xAB}, "{RAND;3;HEX}", "ababab"},
+		{"UUID", &scriptedSource{}, "{RAND;;UUID}", "00000000-0000-4000-8000-000000000000"},
+		{"IPv4", &scriptedSource{fill: 10}, "{RAND;;IPV4}", "10.10.10.10"},
+		{"KeywordChoice", &scriptedSource{values: []int{1, 7}}, "{RAND;2;DIGIT,ABU}", "HB"},
+		{"LengthRange", &scriptedSource{values: []int{2, 0}}, "{RAND;3-6;DIGIT}", "02020"},
+		{"Locale", &scriptedSource{}, "{RAND;;LOCALE}", fastrand.Locales[0]},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := fastrand.NewEngine(fastrand.WithSource(tc.source))
+			if result := engine.RandomizerString(tc.template); result != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, result)
+			}
+		})
+	}
+}
+
+func TestBuiltInSources(t *testing.T) {
+	for _, src := range []fastrand.Source{fastrand.FastSource, fastrand.SecureSource} {
+		engine := fastrand.NewEngine(fastrand.WithSource(src))
+		if result := engine.RandomizerString("{RAND;32;ABL}"); len(result) != 32 {
+			t.Errorf("Expected 32 characters, got %q", result)
+		}
+		if v := src.Intn(10); v < 0 || v >= 10 {
+			t.Errorf("Expected Intn in [0, 10), got %d", v)
+		}
+	}
+}
+
+func TestWithSeed(t *testing.T) {
+	providers := []string{"a.example", "b.example", "c.example", "d.example", "e.example", "f.example"}
+	newEngine := func(seed uint64) *fastrand.FastEngine {
+		return fastrand.NewEngine(fastrand.WithSeed(seed), fastrand.WithMailProviders(providers))
+	}
+
+	t.Run("EmailProvider", func(t *testing.T) {
+		a, b := newEngine(7), newEngine(7)
+		seen := make(map[string]bool)
+		for i := 0; i < 50; i++ {
+			emailA, emailB := a.RandomizerString("{RAND;8;EMAIL}"), b.RandomizerString("{RAND;8;EMAIL}")
+			if emailA != emailB {
+				t.Fatalf("Expected equally seeded engines to agree, got %q and %q", emailA, emailB)
+			}
+			_, provider, _ := strings.Cut(emailA, "@")
+			seen[provider] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("Expected the seeded source to vary the provider, got %v", seen)
+		}
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		template := "{RAND;EMAIL;plus}|{RAND;;UUID}|{RAND;4-12;ABL,DIGIT}|{RAND;;MIME}|{RAND;;METHOD}|{RAND;;COLOR}"
+		a, b, other := newEngine(42), newEngine(42), newEngine(43)
+		resultA, resultB := a.RandomizerString(template), b.RandomizerString(template)
+		if resultA != resultB {
+			t.Errorf("Expected equal seeds to give equal output, got %q and %q", resultA, resultB)
+		}
+		if resultOther := other.RandomizerString(template); resultOther == resultA {
+			t.Errorf("Expected a different seed to give different output, got %q twice", resultA)
+		}
+	})
+}
+
+func TestRandomizeIndexed(t *testing.T) {
+	template := []byte("{RAND;UUID}-{RAND;16;HEX}")
+
+	t.Run("Reproducible", func(t *testing.T) {
+		a := fastrand.NewEngine(fastrand.WithSeed(7))
+		b := fastrand.NewEngine(fastrand.WithSeed(7))
+		for _, i := range []uint64{0, 1, 2, 1 << 40, ^uint64(0)} {
+			first := string(a.RandomizeIndexed(template, i))
+			a.Randomizer(template)
+			if again := string(a.RandomizeIndexed(template, i)); again != first {
+				t.Fatalf("Expected index %d to reproduce %q, got %q", i, first, again)
+			}
+			if other := string(b.RandomizeIndexed(template, i)); other != first {
+				t.Fatalf("Expected an equally seeded engine to give %q for index %d, got %q", first, i, other)
+			}
+		}
+		if string(a.RandomizeIndexed(template, 3)) == string(fastrand.NewEngine(fastrand.WithSeed(8)).RandomizeIndexed(template, 3)) {
+			t.Error("Expected different seeds to give different expansions")
+		}
+		if string(fastrand.NewEngine().RandomizeIndexed(template, 3)) != string(fastrand.NewEngine(fastrand.WithSeed(0)).RandomizeIndexed(template, 3)) {
+			t.Error("Expected an unseeded engine to use seed 0")
+		}
+	})
+
+	t.Run("EngineSequence", func(t *testing.T) {
+		indexed := fastrand.NewEngine(fastrand.WithSeed(7))
+		plain := fastrand.NewEngine(fastrand.WithSeed(7))
+		indexed.RandomizeIndexed(template, 5)
+		if got, want := indexed.RandomizerString("{RAND;32;HEX}"), plain.RandomizerString("{RAND;32;HEX}"); got != want {
+			t.Errorf("Expected RandomizeIndexed to leave the engine's sequence alone, got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Collisions", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithSeed(1))
+		seen := make(map[string]uint64)
+		for i := uint64(0); i < 20000; i++ {
+			value := string(engine.RandomizeIndexed([]byte("{RAND;16;HEX}"), i))
+			if j, dup := seen[value]; dup {
+				t.Fatalf("Expected distinct values, got %q for indices %d and %d", value, j, i)
+			}
+			seen[value] = i
+		}
+
+		// Eight digits over 2000 indices collide with a probability of
+		// about 2%, so more than a few repeats point at correlated indices.
+		digits := make(map[string]bool)
+		for i := uint64(0); i < 2000; i++ {
+			digits[string(engine.RandomizeIndexed([]byte("{RAND;8;DIGIT}"), i))] = true
+		}
+		if repeats := 2000 - len(digits); repeats > 3 {
+			t.Errorf("Expected at most a few repeated values, got %d", repeats)
+		}
+	})
+
+	t.Run("ChoiceSet", func(t *testing.T) {
+		newEngine := func() *fastrand.FastEngine {
+			return fastrand.NewEngine(fastrand.WithSeed(1), fastrand.WithChoiceSet("LETTER", []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}))
+		}
+		template := []byte("{RAND;;LETTER}{RAND;;LETTER}{RAND;;LETTER}{RAND;8;DIGIT}")
+		indexed, plain := newEngine(), newEngine()
+		plain.Randomizer(template)
+		for i := uint64(0); i < 20; i++ {
+			if got, want := string(indexed.RandomizeIndexed(template, i)), string(plain.RandomizeIndexed(template, i)); got != want {
+				t.Fatalf("Expected index %d to give %q whatever ran before, got %q", i, want, got)
+			}
+		}
+		if got, want := indexed.RandomizerString(string(template)), newEngine().RandomizerString(string(template)); got != want {
+			t.Errorf("Expected RandomizeIndexed to leave the engine's sequence alone, got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("SharedPools", func(t *testing.T) {
+		engine := fastrand.NewEngine(fastrand.WithSeed(1))
+		value := string(engine.RandomizeIndexed([]byte("{RAND;;REMEMBER;p;8;HEX}"), 0))
+		if recalled := engine.RandomizerString("{RAND;;RECALL;p}"); recalled != value {
+			t.Errorf("Expected indexed expansions to share REMEMBER pools, got %q, want %q", recalled, value)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package fastrand
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+// callState is what the tags of a single call share.
+type callState struct {
+	uniqueSets map[string][][]byte
+	once       map[string][]byte
+	// afterSpace records whether the output so far ends in whitespace, so
+	// WithCollapseWhitespace can collapse runs spanning several segments.
+	afterSpace bool
+}
+
+// reset forgets the drawn values so the state can serve another call.
+func (s *callState) reset() {
+	clear(s.uniqueSets)
+	clear(s.once)
+	s.afterSpace = false
+}
+
+// takeUnique draws from the named set without replacement.
+func (s *callState) takeUnique(r Source, set string, values []byte) []byte {
+	remaining := s.uniqueSets[set]
+	if len(remaining) == 0 {
+		if len(values) == 0 {
+			return nil
+		}
+		remaining = bytes.Split(values, []byte(","))
+		if s.uniqueSets == nil {
+			s.uniqueSets = make(map[string][][]byte)
+		}
+	}
+
+	i := intN(r, len(remaining))
+	value := remaining[i]
+	remaining[i] = remaining[len(remaining)-1]
+	s.uniqueSets[set] = remaining[:len(remaining)-1]
+	return value
+}
+
+// remember returns the value memoized under name.
+func (s *callState) remember(name string, generate func() []byte) []byte {
+	if value, ok := s.once[name]; ok {
+		return value
+	}
+	value := generate()
+	if s.once == nil {
+		s.once = make(map[string][]byte)
+	}
+	s.once[name] = value
+	return value
+}
+
+// defaultPoolCapacity is how many values a REMEMBER pool keeps by default.
+const defaultPoolCapacity = 64
+
+// poolsMu guards the REMEMBER pools of every engine.
+var poolsMu sync.Mutex
+
+// valuePool is a ring of the latest values remembered under one name.
+type valuePool struct {
+	values [][]byte
+	next   int
+}
+
+// rememberValue adds value to the named pool.
+func (e *FastEngine) rememberValue(name string, value []byte) {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	pool := e.pools[name]
+	if pool == nil {
+		if e.pools == nil {
+			e.pools = make(map[string]*valuePool)
+		}
+		pool = &valuePool{}
+		e.pools[name] = pool
+	}
+	if len(pool.values) < e.poolCapacity {
+		pool.values = append(pool.values, value)
+		return
+	}
+	pool.values[pool.next] = value
+	pool.next = (pool.next + 1) % len(pool.values)
+}
+
+// recallValue returns a random value of the named pool, or nil.
+func (e *FastEngine) recallValue(r Source, name string) []byte {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	pool := e.pools[name]
+	if pool == nil || len(pool.values) == 0 {
+		return nil
+	}
+	return pool.values[intN(r, len(pool.values))]
+}
+
+// collapseSpace collapses ASCII whitespace runs in buffer.B[mark:].
+func (s *callState) collapseSpace(buffer *bytebufferpool.ByteBuffer, mark int) {
+	out := mark
+	for _, c := range buffer.B[mark:] {
+		switch c {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			if s.afterSpace {
+				continue
+			}
+			s.afterSpace = true
+			c = ' '
+		default:
+			s.afterSpace = false
+		}
+		buffer.B[out] = c
+		out++
+	}
+	buffer.B = buffer.B[:out]
+}
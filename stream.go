@@ -0,0 +1,120 @@
+package fastrand
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+const (
+	streamChunkSize = 32 * 1024
+	// maxEncodedTokenLen is the length of the longest encoded delimiter
+	// ("&lbrace;RAND") that normalize may have to see in one piece.
+	maxEncodedTokenLen = 12
+)
+
+func RandomizerStream(r io.Reader, w io.Writer) error {
+	return defaultEngine.RandomizerStream(r, w)
+}
+
+// RandomizerStream expands a template read from r and writes the result to w
+// without holding the whole template in memory. Input is only retained across
+// reads while it may belong to an unfinished tag or encoded delimiter, so a
+// single tag can still be arbitrarily large. A tag left open at EOF is written
+// out literally, as Randomizer does.
+func (e *FastEngine) RandomizerStream(r io.Reader, w io.Writer) error {
+	chunk := make([]byte, streamChunkSize)
+	var raw, pending []byte
+	var openTag bool
+	state := &callState{}
+
+	out := bytebufferpool.Get()
+	defer bytebufferpool.Put(out)
+
+	if e.globalPrefix != "" {
+		if _, err := io.WriteString(w, e.globalPrefix); err != nil {
+			return err
+		}
+	}
+
+	for {
+		n, readErr := r.Read(chunk)
+		raw = append(raw, chunk[:n]...)
+		eof := errors.Is(readErr, io.EOF)
+		if readErr != nil && !eof {
+			return readErr
+		}
+
+		consumed := len(raw)
+		if !eof && e.inputEncoding != RandomizerEncodingNone {
+			consumed = encodedSafeCut(raw)
+		}
+		ready := raw[:consumed]
+		ready = e.decodeInput(ready)
+		pending = append(pending, ready...)
+		raw = append(raw[:0], raw[consumed:]...)
+
+		// While pending holds an open tag only a new '}' can close it, so
+		// skip rescanning it until one arrives.
+		if !eof && openTag && bytes.IndexByte(ready, endTag) == -1 {
+			continue
+		}
+
+		cut := len(pending)
+		if !eof {
+			cut = tagSafeCut(pending)
+		}
+		openTag = cut < len(pending) && bytes.HasPrefix(pending[cut:], startTag)
+		if cut > 0 {
+			out.Reset()
+			e.expand(pending[:cut], out, e.outputEncoding, state)
+			if _, err := out.WriteTo(w); err != nil {
+				return err
+			}
+			pending = append(pending[:0], pending[cut:]...)
+		}
+
+		if eof {
+			if e.globalSuffix == "" {
+				return nil
+			}
+			_, err := io.WriteString(w, e.globalSuffix)
+			return err
+		}
+	}
+}
+
+// encodedSafeCut returns how much of raw can be decoded now.
+func encodedSafeCut(raw []byte) int {
+	from := max(len(raw)-maxEncodedTokenLen+1, 0)
+	if i := bytes.LastIndexAny(raw[from:], "%&"); i != -1 {
+		return from + i
+	}
+	return len(raw)
+}
+
+// tagSafeCut returns how much of payload can be expanded now.
+func tagSafeCut(payload []byte) int {
+	cursor := 0
+	for {
+		startIndex := bytes.Index(payload[cursor:], startTag)
+		if startIndex == -1 {
+			break
+		}
+		startIndex += cursor
+		endIndex := findTagEnd(payload[startIndex:])
+		if endIndex == -1 {
+			return startIndex
+		}
+		cursor = startIndex + endIndex + 1
+	}
+
+	for k := min(len(startTag)-1, len(payload)); k > 0; k-- {
+		if bytes.HasSuffix(payload, startTag[:k]) {
+			return len(payload) - k
+		}
+	}
+	return len(payload)
+}
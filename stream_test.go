@@ -0,0 +1,76 @@
+package fastrand_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestRandomizerStream(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithMimeTypes([]string{"text/plain"}))
+
+	deterministic := []string{
+		"plain text without tags",
+		"a {RAND;20;CYCLIC} b {RAND;;SHA256;abc} c",
+		"{RAND;;MIME}{RANDOM;;MD5;{RAND;;MIME}}",
+		"encoded %7BRAND%3B10%3BCYCLIC%7D and &lbrace;RAND&semi;&semi;SHA1&semi;x&rbrace; tags",
+		"literal {RANDX} and braces {} and 100% & more",
+		"unterminated at eof {RAND;;SHA256;abc",
+		"partial start at eof {RA",
+		"a tag larger than the read buffer {RAND;;SHA1;" + strings.Repeat("x", 100000) + "}",
+	}
+
+	for _, template := range deterministic {
+		name := template
+		if len(name) > 40 {
+			name = name[:40]
+		}
+		t.Run(name, func(t *testing.T) {
+			expected := engine.Randomizer([]byte(template))
+
+			var out bytes.Buffer
+			if err := engine.RandomizerStream(iotest.OneByteReader(strings.NewReader(template)), &out); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(out.Bytes(), expected) {
+				t.Errorf("Stream output differs from Randomizer:\n got: %.200q\nwant: %.200q", out.Bytes(), expected)
+			}
+
+			out.Reset()
+			if err := engine.RandomizerStream(strings.NewReader(template), &out); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(out.Bytes(), expected) {
+				t.Errorf("Stream output differs from Randomizer for a single read")
+			}
+		})
+	}
+
+	t.Run("Random", func(t *testing.T) {
+		var out bytes.Buffer
+		template := "id={RAND;8;DIGIT};"
+		if err := fastrand.RandomizerStream(iotest.OneByteReader(strings.NewReader(strings.Repeat(template, 100))), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(out.String(), ";"), ";")
+		if len(lines) != 100 {
+			t.Fatalf("Expected 100 values, got %d", len(lines))
+		}
+		for _, line := range lines {
+			if len(line) != len("id=")+8 || !strings.HasPrefix(line, "id=") {
+				t.Fatalf("Unexpected value %q", line)
+			}
+			checkCharset(t, []byte(line[3:]), fastrand.CharsDigits)
+		}
+	})
+
+	t.Run("ReadError", func(t *testing.T) {
+		err := fastrand.RandomizerStream(iotest.ErrReader(iotest.ErrTimeout), &bytes.Buffer{})
+		if err != iotest.ErrTimeout {
+			t.Errorf("Expected read error to be returned, got %v", err)
+		}
+	})
+}
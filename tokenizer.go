@@ -0,0 +1,109 @@
+package fastrand
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type TokenKind int
+
+const (
+	LiteralToken TokenKind = iota + 1
+	TagToken
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case LiteralToken:
+		return "literal"
+	case TagToken:
+		return "tag"
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(k))
+}
+
+// Token is a piece of a template as the engine lexes it. Start and End are
+// byte offsets into the template after input decoding, and Raw is
+// template[Start:End]. For tags, Length, Keyword and Args hold the raw
+// fields of {RAND;LENGTH;KEYWORD;ARGS}; nested tags stay inside Args
+// unexpanded. A Malformed literal is text starting with {RAND that is not
+// expanded, such as an unterminated tag or a look-alike like {RANDX}.
+type Token struct {
+	Kind      TokenKind
+	Start     int
+	End       int
+	Raw       []byte
+	Length    []byte
+	Keyword   []byte
+	Args      []byte
+	Malformed bool
+}
+
+func Tokenize(template []byte) []Token {
+	return defaultEngine.Tokenize(template)
+}
+
+// Tokenize splits template into literal and tag tokens without generating
+// anything. Encoded delimiters are decoded first according to the engine's
+// input encoding, so an engine built with RandomizerEncodingNone lexes the
+// template exactly as written. Only top-level tags become tokens.
+func (e *FastEngine) Tokenize(template []byte) []Token {
+	template = e.decodeInput(template)
+
+	var tokens []Token
+	literal := func(start, end int, malformed bool) {
+		if start < end {
+			tokens = append(tokens, Token{Kind: LiteralToken, Start: start, End: end, Raw: template[start:end], Malformed: malformed})
+		}
+	}
+
+	cursor := 0
+	for {
+		startIndex := bytes.Index(template[cursor:], startTag)
+		if startIndex == -1 {
+			literal(cursor, len(template), false)
+			return tokens
+		}
+		startIndex += cursor
+		literal(cursor, startIndex, false)
+
+		endIndex := findTagEnd(template[startIndex:])
+		if endIndex == -1 {
+			literal(startIndex, len(template), true)
+			return tokens
+		}
+		end := startIndex + endIndex + 1
+		if tag := template[startIndex : end-1]; isWellFormedTag(tag) {
+			tokens = append(tokens, lexTag(tag, startIndex, end, template[startIndex:end]))
+		} else {
+			literal(startIndex, end, true)
+		}
+		cursor = end
+	}
+}
+
+// lexTag splits a well-formed tag into its raw fields.
+func lexTag(tag []byte, start, end int, raw []byte) Token {
+	token := Token{Kind: TagToken, Start: start, End: end, Raw: raw}
+	body := tag[len(startTag):]
+	if bytes.HasPrefix(body, startTagOpt) {
+		body = body[len(startTagOpt):]
+	}
+	if len(body) == 0 {
+		return token
+	}
+	body = body[1:]
+
+	lenPart, rest, hasKeyword := bytes.Cut(body, []byte{sepTag})
+	if !hasKeyword {
+		if _, sized := parseSizeSuffix(lenPart); isNumericLength(lenPart) || sized {
+			token.Length = lenPart
+		} else {
+			token.Keyword = lenPart
+		}
+		return token
+	}
+	token.Length = lenPart
+	token.Keyword, token.Args, _ = bytes.Cut(rest, []byte{sepTag})
+	return token
+}
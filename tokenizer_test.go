@@ -0,0 +1,62 @@
+package fastrand_test
+
+import (
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestTokenize(t *testing.T) {
+	template := "id={RAND;8;HEX} user={RANDOM;UUID} h={RAND;;SHA1;{RAND;4;ABL}} {RANDX} {RAND} tail {RAND;5"
+	type want struct {
+		kind      fastrand.TokenKind
+		raw       string
+		length    string
+		keyword   string
+		args      string
+		malformed bool
+	}
+	expected := []want{
+		{kind: fastrand.LiteralToken, raw: "id="},
+		{kind: fastrand.TagToken, raw: "{RAND;8;HEX}", length: "8", keyword: "HEX"},
+		{kind: fastrand.LiteralToken, raw: " user="},
+		{kind: fastrand.TagToken, raw: "{RANDOM;UUID}", keyword: "UUID"},
+		{kind: fastrand.LiteralToken, raw: " h="},
+		{kind: fastrand.TagToken, raw: "{RAND;;SHA1;{RAND;4;ABL}}", keyword: "SHA1", args: "{RAND;4;ABL}"},
+		{kind: fastrand.LiteralToken, raw: " "},
+		{kind: fastrand.LiteralToken, raw: "{RANDX}", malformed: true},
+		{kind: fastrand.LiteralToken, raw: " "},
+		{kind: fastrand.TagToken, raw: "{RAND}"},
+		{kind: fastrand.LiteralToken, raw: " tail "},
+		{kind: fastrand.LiteralToken, raw: "{RAND;5", malformed: true},
+	}
+
+	tokens := fastrand.Tokenize([]byte(template))
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %+v", len(expected), len(tokens), tokens)
+	}
+	offset := 0
+	for i, tok := range tokens {
+		w := expected[i]
+		if tok.Kind != w.kind || string(tok.Raw) != w.raw || string(tok.Length) != w.length ||
+			string(tok.Keyword) != w.keyword || string(tok.Args) != w.args || tok.Malformed != w.malformed {
+			t.Errorf("Token %d: got {%v %q len=%q kw=%q args=%q malformed=%v}, want %+v",
+				i, tok.Kind, tok.Raw, tok.Length, tok.Keyword, tok.Args, tok.Malformed, w)
+		}
+		if tok.Start != offset || tok.End != offset+len(tok.Raw) || template[tok.Start:tok.End] != string(tok.Raw) {
+			t.Errorf("Token %d: bad offsets [%d, %d)", i, tok.Start, tok.End)
+		}
+		offset = tok.End
+	}
+
+	t.Run("Encoded", func(t *testing.T) {
+		encoded := []byte("%7BRAND%3B4%3BDIGIT%7D")
+		if tokens := fastrand.Tokenize(encoded); len(tokens) != 1 || tokens[0].Kind != fastrand.TagToken || string(tokens[0].Keyword) != "DIGIT" {
+			t.Errorf("Expected the encoded tag to be decoded, got %+v", tokens)
+		}
+		engine := fastrand.NewEngine(fastrand.WithInputEncoding(fastrand.RandomizerEncodingNone))
+		if tokens := engine.Tokenize(encoded); len(tokens) != 1 || tokens[0].Kind != fastrand.LiteralToken {
+			t.Errorf("Expected a single literal without input decoding, got %+v", tokens)
+		}
+	})
+}
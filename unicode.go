@@ -0,0 +1,157 @@
+package fastrand
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// randomRune returns a valid, non-control scalar value of 1 to 4 bytes.
+func randomRune(r Source) rune {
+	for {
+		var c rune
+		switch intN(r, 4) {
+		case 0:
+			c = rune(intRange(r, 0x20, 0x7E))
+		case 1:
+			c = rune(intRange(r, 0xA0, 0x7FF))
+		case 2:
+			c = rune(intRange(r, 0x800, 0xFFFF))
+		default:
+			c = rune(intRange(r, 0x10000, utf8.MaxRune))
+		}
+		if isScalarCharacter(c) {
+			return c
+		}
+	}
+}
+
+func isScalarCharacter(r rune) bool {
+	if r >= 0xD800 && r <= 0xDFFF {
+		return false
+	}
+	if r >= 0xFDD0 && r <= 0xFDEF {
+		return false
+	}
+	return r&0xFFFE != 0xFFFE
+}
+
+func generateUTF8(r Source, runes int) []byte {
+	out := make([]byte, 0, runes*utf8.UTFMax)
+	for i := 0; i < runes; i++ {
+		out = utf8.AppendRune(out, randomRune(r))
+	}
+	return out
+}
+
+// unicodeBlock is an inclusive range of code points.
+type unicodeBlock struct {
+	lo, hi rune
+}
+
+// unicodeBlocks maps the block names UNICODE accepts to their code points.
+var unicodeBlocks = map[string]unicodeBlock{
+	"latin":    {0x0020, 0x017F},
+	"greek":    {0x0370, 0x03FF},
+	"cyrillic": {0x0400, 0x04FF},
+	"arabic":   {0x0600, 0x06FF},
+	"cjk":      {0x4E00, 0x9FFF},
+}
+
+func unicodeBlockFor(name []byte) unicodeBlock {
+	if block, ok := unicodeBlocks[strings.ToLower(string(name))]; ok {
+		return block
+	}
+	return unicodeBlocks["latin"]
+}
+
+// generateUnicodeBlock emits runes printable characters from block.
+func generateUnicodeBlock(r Source, runes int, block unicodeBlock) []byte {
+	out := make([]byte, 0, runes*utf8.UTFMax)
+	for i := 0; i < runes; {
+		c := rune(intRange(r, int(block.lo), int(block.hi)))
+		if unicode.IsPrint(c) {
+			out = utf8.AppendRune(out, c)
+			i++
+		}
+	}
+	return out
+}
+
+// printableRunes counts the runes generateUnicodeBlock can draw from block.
+func printableRunes(block unicodeBlock) int {
+	n := 0
+	for c := block.lo; c <= block.hi; c++ {
+		if unicode.IsPrint(c) {
+			n++
+		}
+	}
+	return n
+}
+
+var badUTF8Classes = map[string]func(r Source) []byte{
+	"continuation": func(r Source) []byte {
+		return []byte{byte(intRange(r, 0x80, 0xBF))}
+	},
+	"overlong": func(r Source) []byte {
+		if randBool(r) {
+			return []byte{byte(intRange(r, 0xC0, 0xC1)), byte(intRange(r, 0x80, 0xBF))}
+		}
+		return []byte{0xE0, byte(intRange(r, 0x80, 0x9F)), byte(intRange(r, 0x80, 0xBF))}
+	},
+	// A truncated sequence is terminated by an ASCII byte so that a following
+	// continuation byte cannot complete it.
+	"truncated": func(r Source) []byte {
+		if randBool(r) {
+			return []byte{byte(intRange(r, 0xC2, 0xDF)), byte(intRange(r, 0x41, 0x5A))}
+		}
+		return []byte{byte(intRange(r, 0xE1, 0xEC)), byte(intRange(r, 0x80, 0xBF)), byte(intRange(r, 0x41, 0x5A))}
+	},
+	"surrogate": func(r Source) []byte {
+		return []byte{0xED, byte(intRange(r, 0xA0, 0xBF)), byte(intRange(r, 0x80, 0xBF))}
+	},
+	"invalid": func(r Source) []byte {
+		return []byte{byte(intRange(r, 0xF5, 0xFF))}
+	},
+}
+
+var badUTF8DefaultClasses = []string{"continuation", "overlong", "truncated", "surrogate", "invalid"}
+
+// generateBadUTF8 emits exactly length bytes of malformed UTF-8.
+func generateBadUTF8(r Source, length int, classes []byte) []byte {
+	var generators []func(r Source) []byte
+	for _, class := range bytes.Split(bytes.ToLower(classes), []byte(",")) {
+		if gen, ok := badUTF8Classes[string(bytes.TrimSpace(class))]; ok {
+			generators = append(generators, gen)
+		}
+	}
+	if len(generators) == 0 {
+		for _, class := range badUTF8DefaultClasses {
+			generators = append(generators, badUTF8Classes[class])
+		}
+	}
+
+	out := make([]byte, 0, length+utf8.UTFMax)
+	for len(out) < length {
+		out = append(out, pick(r, generators)(r)...)
+	}
+	return out[:length]
+}
+
+// spongeCase upper- or lower-cases each letter of s at random.
+func spongeCase(r Source, s []byte) []byte {
+	out := make([]byte, 0, len(s))
+	for len(s) > 0 {
+		c, size := utf8.DecodeRune(s)
+		if c == utf8.RuneError || !unicode.IsLetter(c) {
+			out = append(out, s[:size]...)
+		} else if randBool(r) {
+			out = utf8.AppendRune(out, unicode.ToUpper(c))
+		} else {
+			out = utf8.AppendRune(out, unicode.ToLower(c))
+		}
+		s = s[size:]
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package fastrand_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestUTF8Keyword(t *testing.T) {
+	widths := make(map[int]int)
+	for i := 0; i < 200; i++ {
+		result := fastrand.Randomizer([]byte("{RAND;10;UTF8}"))
+		if !utf8.Valid(result) {
+			t.Fatalf("Expected valid UTF-8, got %q", result)
+		}
+		if n := utf8.RuneCount(result); n != 10 {
+			t.Fatalf("Expected 10 runes, got %d in %q", n, result)
+		}
+		for len(result) > 0 {
+			r, size := utf8.DecodeRune(result)
+			if r >= 0xD800 && r <= 0xDFFF || r >= 0xFDD0 && r <= 0xFDEF || r&0xFFFE == 0xFFFE {
+				t.Fatalf("Unexpected surrogate or noncharacter %U", r)
+			}
+			widths[size]++
+			result = result[size:]
+		}
+	}
+	for size := 1; size <= 4; size++ {
+		if widths[size] == 0 {
+			t.Errorf("Expected some %d-byte runes, got none", size)
+		}
+	}
+}
+
+func TestBadUTF8Keyword(t *testing.T) {
+	templates := []string{
+		"{RAND;8;BADUTF8}",
+		"{RAND;1;BADUTF8}",
+		"{RAND;7;BADUTF8;continuation}",
+		"{RAND;5;BADUTF8;overlong}",
+		"{RAND;9;BADUTF8;truncated}",
+		"{RAND;4;BADUTF8;surrogate}",
+		"{RAND;3;BADUTF8;invalid}",
+		"{RAND;11;BADUTF8;truncated,continuation}",
+		"{RAND;6;BADUTF8;unknown}",
+	}
+	for _, template := range templates {
+		t.Run(template, func(t *testing.T) {
+			var expectedLen int
+			fmt.Sscanf(template, "{RAND;%d;", &expectedLen)
+			for i := 0; i < 200; i++ {
+				result := fastrand.Randomizer([]byte(template))
+				if len(result) != expectedLen {
+					t.Fatalf("Expected %d bytes, got %d", expectedLen, len(result))
+				}
+				if utf8.Valid(result) {
+					t.Fatalf("Expected invalid UTF-8, got valid %q", result)
+				}
+			}
+		})
+	}
+}
+
+func TestSpongeKeyword(t *testing.T) {
+	const input = "hello world, ÄÖü 123!"
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		result := fastrand.RandomizerString("{RAND;;SPONGE;" + input + "}")
+		if !utf8.ValidString(result) {
+			t.Fatalf("Expected valid UTF-8, got %q", result)
+		}
+		if !strings.EqualFold(result, input) {
+			t.Fatalf("Expected the letters of %q in any case, got %q", input, result)
+		}
+		seen[result] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Expected the case pattern to vary across runs")
+	}
+
+	nested := fastrand.RandomizerString("{RAND;;SPONGE;{RAND;12;ABL}}")
+	if len(nested) != 12 || strings.ContainsFunc(nested, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		t.Errorf("Expected 12 letters from the nested tag, got %q", nested)
+	}
+}
+
+func TestEmojiKeyword(t *testing.T) {
+	known := make(map[string]bool, len(fastrand.Emoji))
+	longest := 0
+	for _, e := range fastrand.Emoji {
+		known[e] = true
+		longest = max(longest, len(e))
+	}
+
+	// countEmoji splits s into list entries by longest match, so sequences
+	// such as flags or ZWJ families count once.
+	countEmoji := func(s string) (int, bool) {
+		count := 0
+		for len(s) > 0 {
+			matched := 0
+			for n := min(longest, len(s)); n > 0; n-- {
+				if known[s[:n]] {
+					matched = n
+					break
+				}
+			}
+			if matched == 0 {
+				return count, false
+			}
+			s = s[matched:]
+			count++
+		}
+		return count, true
+	}
+
+	for _, n := range []int{1, 5, 40} {
+		template := fmt.Sprintf("{RAND;%d;EMOJI}", n)
+		for i := 0; i < 100; i++ {
+			result := fastrand.RandomizerString(template)
+			if !utf8.ValidString(result) {
+				t.Fatalf("Expected valid UTF-8, got %q", result)
+			}
+			if count, ok := countEmoji(result); !ok || count != n {
+				t.Fatalf("Expected %d emoji from the list, got %d (ok=%v) in %q", n, count, ok, result)
+			}
+		}
+	}
+}
+
+func TestUnicodeKeyword(t *testing.T) {
+	testCases := []struct {
+		block  string
+		lo, hi rune
+	}{
+		{"cyrillic", 0x0400, 0x04FF},
+		{"GREEK", 0x0370, 0x03FF},
+		{"arabic", 0x0600, 0x06FF},
+		{"cjk", 0x4E00, 0x9FFF},
+		{"latin", 0x0020, 0x017F},
+		{"klingon", 0x0020, 0x017F},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.block, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				result := fastrand.RandomizerString(fmt.Sprintf("{RAND;10;UNICODE;%s}", tc.block))
+				if count := utf8.RuneCountInString(result); count != 10 {
+					t.Fatalf("Expected 10 runes, got %d in %q", count, result)
+				}
+				for _, c := range result {
+					if c < tc.lo || c > tc.hi || !unicode.IsPrint(c) {
+						t.Fatalf("Rune %U is not a printable %s character in %q", c, tc.block, result)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package fastrand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxTagDepth is how deeply Validate allows tags to nest.
+const maxTagDepth = 8
+
+type ErrorKind int
+
+const (
+	UnterminatedTag ErrorKind = iota + 1
+	UnknownKeyword
+	BadLength
+	BadRange
+	DepthExceeded
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case UnterminatedTag:
+		return "unterminated tag"
+	case UnknownKeyword:
+		return "unknown keyword"
+	case BadLength:
+		return "bad length"
+	case BadRange:
+		return "bad range"
+	case DepthExceeded:
+		return "depth exceeded"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// TemplateError describes the first problem found in a template. Offset is the
+// byte offset of the offending tag in the template after input decoding, and
+// Tag holds the tag without its closing brace.
+type TemplateError struct {
+	Offset int
+	Tag    []byte
+	Kind   ErrorKind
+	Msg    string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("fastrand: %s at offset %d: %s", e.Kind, e.Offset, e.Msg)
+}
+
+func Validate(template []byte) error {
+	return defaultEngine.Validate(template)
+}
+
+func RandomizeStrict(template []byte) ([]byte, error) {
+	return defaultEngine.RandomizeStrict(template)
+}
+
+// Validate checks every tag in template, including nested ones, against the
+// engine's configuration and returns a *TemplateError for the first tag that
+// Randomizer would silently expand as something other than what it says.
+func (e *FastEngine) Validate(template []byte) error {
+	template = e.decodeInput(template)
+	return e.validate(template, 0, 0)
+}
+
+// ErrLowEntropy is returned by RandomizeStrict when a template is estimated
+// to carry fewer bits than set with WithMinEntropy.
+var ErrLowEntropy = errors.New("fastrand: template entropy below minimum")
+
+// RandomizeStrict is Randomizer for templates that pass Validate and, with
+// WithMinEntropy, carry enough entropy.
+func (e *FastEngine) RandomizeStrict(template []byte) ([]byte, error) {
+	if err := e.Validate(template); err != nil {
+		return nil, err
+	}
+	if e.minEntropy > 0 {
+		if bits := e.EntropyBits(template); bits < e.minEntropy {
+			return nil, fmt.Errorf("%w: %.1f bits, want at least %.1f", ErrLowEntropy, bits, e.minEntropy)
+		}
+	}
+	return e.Randomizer(template), nil
+}
+
+func (e *FastEngine) validate(template []byte, base, depth int) error {
+	cursor := 0
+	for {
+		startIndex := bytes.Index(template[cursor:], startTag)
+		if startIndex == -1 {
+			return nil
+		}
+		startIndex += cursor
+		endIndex := findTagEnd(template[startIndex:])
+		if endIndex == -1 {
+			return &TemplateError{
+				Offset: base + startIndex,
+				Tag:    template[startIndex:],
+				Kind:   UnterminatedTag,
+				Msg:    "missing closing brace",
+			}
+		}
+		tag := template[startIndex : startIndex+endIndex]
+		cursor = startIndex + endIndex + 1
+		if !isWellFormedTag(tag) {
+			continue
+		}
+		if err := e.validateTag(tag, base+startIndex, depth); err != nil {
+			return err
+		}
+	}
+}
+
+func (e *FastEngine) validateTag(tag []byte, offset, depth int) error {
+	fail := func(kind ErrorKind, format string, args ...any) error {
+		return &TemplateError{Offset: offset, Tag: tag, Kind: kind, Msg: fmt.Sprintf(format, args...)}
+	}
+	if depth >= maxTagDepth {
+		return fail(DepthExceeded, "tags nested more than %d deep", maxTagDepth)
+	}
+
+	body := tag[len(startTag):]
+	if bytes.HasPrefix(body, startTagOpt) {
+		body = body[len(startTagOpt):]
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	body = body[1:]
+	bodyOffset := offset + len(tag) - len(body)
+
+	var typeKeyword, lenPart []byte
+	if sepIndex := bytes.IndexByte(body, sepTag); sepIndex == -1 {
+		lenPart = body
+	} else {
+		lenPart = body[:sepIndex]
+		typeKeyword = body[sepIndex+1:]
+	}
+
+	bounds := e.lengthBoundsFor(typeKeyword)
+	if lengths, _ := e.estimateLengths(lenPart, bounds); lengths == nil && len(lenPart) > 0 {
+		switch {
+		case typeKeyword == nil && !isNumericLength(lenPart):
+			typeKeyword = lenPart
+		case e.rangesEnabled && bytes.Contains(lenPart, []byte("-")):
+			if _, _, _, inverted := e.parseRange(lenPart, bounds); inverted {
+				return fail(BadRange, "range %q is inverted", lenPart)
+			}
+			return fail(BadRange, "range %q is not within [%d, %d]", lenPart, bounds.min, bounds.max)
+		default:
+			return fail(BadLength, "length %q is not within [%d, %d]", lenPart, bounds.min, bounds.max)
+		}
+	}
+
+	var arg []byte
+	argOffset := 0
+	if argIndex := bytes.IndexByte(typeKeyword, sepTag); argIndex != -1 {
+		arg = typeKeyword[argIndex+1:]
+		typeKeyword = typeKeyword[:argIndex]
+		argOffset = bodyOffset + len(body) - len(arg)
+	}
+
+	if len(typeKeyword) > 0 {
+		choices := [][]byte{typeKeyword}
+		if e.keywordChoicesEnabled {
+			choices = bytes.Split(typeKeyword, []byte(","))
+		}
+		for _, choice := range choices {
+			if !e.isKnownKeyword(choice) {
+				return fail(UnknownKeyword, "keyword %q is not known or not enabled", strings.ToUpper(string(choice)))
+			}
+		}
+	}
+
+	if bytes.Contains(arg, startTag) {
+		return e.validate(arg, argOffset, depth+1)
+	}
+	return nil
+}
+
+// isNumericLength reports whether b can only be a length.
+func isNumericLength(b []byte) bool {
+	for _, c := range b {
+		if (c < '0' || c > '9') && c != '-' && c != ',' {
+			return false
+		}
+	}
+	return true
+}
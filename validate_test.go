@@ -0,0 +1,108 @@
+package fastrand_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SyNdicateFoundation/fastrand"
+)
+
+func TestValidate(t *testing.T) {
+	deep := strings.Repeat("{RAND;;MD5;", 9) + "x" + strings.Repeat("}", 9)
+
+	testCases := []struct {
+		name     string
+		template string
+		kind     fastrand.ErrorKind
+		offset   int
+	}{
+		{"Valid", "a {RAND;8;DIGIT} b {RAND;UUID,HEX} {RAND} {RAND;1k;BYTES} {RAND;;MD5;{RAND;4;ABL}}", 0, 0},
+		{"LookAlikeIsLiteral", "{RANDX}", 0, 0},
+		{"Unterminated", "ok {RAND;8;DIGIT} {RAND;8;DIGIT", fastrand.UnterminatedTag, 18},
+		{"UnknownKeyword", "id={RAND;8;NOPE}", fastrand.UnknownKeyword, 3},
+		{"UnknownChoice", "{RAND;8;HEX,NOPE}", fastrand.UnknownKeyword, 0},
+		{"BareUnknownKeyword", "x{RAND;NOPE}", fastrand.UnknownKeyword, 1},
+		{"LengthTooLarge", "{RAND;500;ABL}", fastrand.BadLength, 0},
+		{"LengthNotNumeric", "__{RAND;abc;ABL}", fastrand.BadLength, 2},
+		{"RangeOutOfBounds", "{RAND;5-500;ABL}", fastrand.BadRange, 0},
+		{"NestedUnknown", "{RAND;;MD5;ab{RAND;;NOPE}}", fastrand.UnknownKeyword, 13},
+		{"DepthExceeded", deep, fastrand.DepthExceeded, 8 * len("{RAND;;MD5;")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := fastrand.Validate([]byte(tc.template))
+			if tc.kind == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			var tmplErr *fastrand.TemplateError
+			if !errors.As(err, &tmplErr) {
+				t.Fatalf("Expected a *TemplateError, got %v", err)
+			}
+			if tmplErr.Kind != tc.kind || tmplErr.Offset != tc.offset {
+				t.Errorf("Expected %v at %d, got %v at %d (%v)", tc.kind, tc.offset, tmplErr.Kind, tmplErr.Offset, err)
+			}
+			if !strings.HasPrefix(tc.template[tmplErr.Offset:], string(tmplErr.Tag)) {
+				t.Errorf("Expected Tag %q to appear at the reported offset", tmplErr.Tag)
+			}
+		})
+	}
+}
+
+func TestValidateEngineConfig(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithDisabledKeywords("EMAIL"), fastrand.WithKeywordAlias("TOKEN", "HEX"))
+	if err := engine.Validate([]byte("{RAND;;TOKEN}")); err != nil {
+		t.Errorf("Expected aliases to validate, got %v", err)
+	}
+	var tmplErr *fastrand.TemplateError
+	if err := engine.Validate([]byte("{RAND;;EMAIL}")); !errors.As(err, &tmplErr) || tmplErr.Kind != fastrand.UnknownKeyword {
+		t.Errorf("Expected disabled keywords to be rejected, got %v", err)
+	}
+}
+
+func TestRandomizeStrict(t *testing.T) {
+	result, err := fastrand.RandomizeStrict([]byte("{RAND;8;DIGIT}"))
+	if err != nil || len(result) != 8 {
+		t.Fatalf("Expected 8 digits, got %q (%v)", result, err)
+	}
+	if result, err := fastrand.RandomizeStrict([]byte("{RAND;8;NOPE}")); err == nil || result != nil {
+		t.Errorf("Expected an error and no output, got %q (%v)", result, err)
+	}
+}
+
+func TestMinEntropy(t *testing.T) {
+	engine := fastrand.NewEngine(fastrand.WithMinEntropy(64))
+
+	testCases := []struct {
+		template string
+		low      bool
+	}{
+		{"token={RAND;2;DIGIT}", true},
+		{"a very long literal prefix that carries no randomness at all {RAND;4;HEX}", true},
+		{"{RAND;16;HEX}", false},
+		{"sess_{RAND;20;ABR}", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			result, err := engine.RandomizeStrict([]byte(tc.template))
+			if tc.low {
+				if !errors.Is(err, fastrand.ErrLowEntropy) {
+					t.Errorf("Expected ErrLowEntropy, got %v", err)
+				}
+				return
+			}
+			if err != nil || len(result) == 0 {
+				t.Errorf("Expected the template to pass, got %q, %v", result, err)
+			}
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		if _, err := fastrand.RandomizeStrict([]byte("{RAND;1;DIGIT}")); err != nil {
+			t.Errorf("Expected no entropy check without WithMinEntropy, got %v", err)
+		}
+	})
+}